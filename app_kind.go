@@ -0,0 +1,251 @@
+package dotnetexecute
+
+import (
+	"bytes"
+	"debug/elf"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// AppKind is the way a .NET app comes to the build. Detect and Build agree
+// on it: an app that is built from its project file is a source app, even
+// though Build runs the output that was published from it.
+type AppKind string
+
+const (
+	// AppKindSource is an app that is built from its project file.
+	AppKindSource AppKind = "source"
+
+	// AppKindFrameworkDependentDeployment is a published app that is run with
+	// `dotnet <app>.dll` on a shared runtime.
+	AppKindFrameworkDependentDeployment AppKind = "framework-dependent-deployment"
+
+	// AppKindFrameworkDependentExecutable is a published app whose apphost
+	// executable runs it on a shared runtime.
+	AppKindFrameworkDependentExecutable AppKind = "framework-dependent-executable"
+
+	// AppKindSelfContained is a published app that carries its own runtime.
+	AppKindSelfContained AppKind = "self-contained"
+
+	// AppKindSingleFile is an app that is published as a single-file bundle,
+	// either framework-dependent or self-contained.
+	AppKindSingleFile AppKind = "single-file"
+
+	// AppKindNativeAOT is an app that is compiled ahead of time to a native
+	// executable, which needs no .NET runtime.
+	AppKindNativeAOT AppKind = "native-aot"
+)
+
+// AppKindPlanEntry is the build plan entry that Detect provides, so that
+// later buildpacks can require it to only pass detection for .NET apps.
+// Detect requires it with an app-kind in its metadata, which only Build
+// reads; later buildpacks learn the kind from AppKindEnv.
+const AppKindPlanEntry = "dotnet-app-kind"
+
+// AppKindEnv is the environment variable that tells later buildpacks and
+// the running app the app kind.
+const AppKindEnv = "BPI_DOTNET_APP_KIND"
+
+// nativeAOTSections are the ELF sections that the ILCompiler puts managed
+// code in.
+var nativeAOTSections = []string{"__managedcode", ".managedcode"}
+
+// ClassifyApp returns the kind of app described by runtimeConfig, whose
+// files are in appDir. An app with a project file is built from source, so
+// it is a source app whatever else is present. An app with neither a project
+// file, a runtimeconfig.json nor an executable has no kind.
+func ClassifyApp(runtimeConfig RuntimeConfig, appDir, projectFile string) (AppKind, error) {
+	if projectFile != "" {
+		return AppKindSource, nil
+	}
+
+	if runtimeConfig.Path == "" && runtimeConfig.AppName == "" {
+		return "", nil
+	}
+
+	if runtimeConfig.Executable {
+		executable := filepath.Join(appDir, runtimeConfig.AppName)
+
+		elfFile, err := isELF(executable)
+		if err != nil {
+			return "", err
+		}
+
+		if elfFile {
+			_, isBundle, err := ReadSingleFileBundle(executable)
+			if err != nil {
+				return "", err
+			}
+			if isBundle {
+				return AppKindSingleFile, nil
+			}
+
+			nativeAOT, err := isNativeAOT(executable)
+			if err != nil {
+				return "", err
+			}
+			if nativeAOT {
+				return AppKindNativeAOT, nil
+			}
+		}
+	}
+
+	switch {
+	case runtimeConfig.RuntimeVersion == "":
+		return AppKindSelfContained, nil
+	case runtimeConfig.Executable:
+		return AppKindFrameworkDependentExecutable, nil
+	default:
+		return AppKindFrameworkDependentDeployment, nil
+	}
+}
+
+// FindExecutableApp looks in dir for an app that is published without a
+// runtimeconfig.json: a single-file bundle, which carries its
+// runtimeconfig.json inside, or a native AOT executable. When entryAssembly
+// is set, only that file is considered. The RuntimeConfig it returns has no
+// Path, and it reports false when there is no such app.
+func FindExecutableApp(dir, entryAssembly string) (RuntimeConfig, bool, error) {
+	var candidates []string
+	if entryAssembly != "" {
+		if strings.HasSuffix(entryAssembly, ".dll") {
+			return RuntimeConfig{}, false, nil
+		}
+		candidates = []string{filepath.Join(dir, entryAssembly)}
+	} else {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return RuntimeConfig{}, false, nil
+			}
+			return RuntimeConfig{}, false, err
+		}
+
+		for _, entry := range entries {
+			if entry.Type().IsRegular() && !isSharedLibraryName(entry.Name()) {
+				candidates = append(candidates, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+
+	var apps []RuntimeConfig
+	for _, candidate := range candidates {
+		app, ok, err := readExecutableApp(candidate)
+		if err != nil {
+			return RuntimeConfig{}, false, err
+		}
+		if ok {
+			apps = append(apps, app)
+		}
+	}
+
+	switch len(apps) {
+	case 0:
+		return RuntimeConfig{}, false, nil
+	case 1:
+		return apps[0], true, nil
+	}
+
+	var names []string
+	for _, app := range apps {
+		names = append(names, app.AppName)
+	}
+
+	return RuntimeConfig{}, false, fmt.Errorf("found multiple app executables in %s, set BP_DOTNET_ENTRY_ASSEMBLY to choose one of: %s", dir, strings.Join(names, ", "))
+}
+
+func readExecutableApp(path string) (RuntimeConfig, bool, error) {
+	elfFile, err := isELF(path)
+	if err != nil || !elfFile {
+		return RuntimeConfig{}, false, err
+	}
+
+	app := RuntimeConfig{
+		AppName:    filepath.Base(path),
+		Executable: true,
+	}
+
+	bundle, isBundle, err := ReadSingleFileBundle(path)
+	if err != nil {
+		return RuntimeConfig{}, false, err
+	}
+
+	if isBundle {
+		if len(bundle.RuntimeConfig) > 0 {
+			err = decodeRuntimeConfig(bytes.NewReader(bundle.RuntimeConfig), &app)
+			if err != nil {
+				return RuntimeConfig{}, false, fmt.Errorf("failed to decode the bundled runtimeconfig.json of %s: %w", path, err)
+			}
+		}
+
+		return app, true, nil
+	}
+
+	nativeAOT, err := isNativeAOT(path)
+	if err != nil || !nativeAOT {
+		return RuntimeConfig{}, false, err
+	}
+
+	return app, true, nil
+}
+
+// isELF reports whether the regular file at path starts with the ELF magic
+// number.
+func isELF(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if !info.Mode().IsRegular() {
+		return false, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	magic := make([]byte, len(elf.ELFMAG))
+	_, err = io.ReadFull(file, magic)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return string(magic) == elf.ELFMAG, nil
+}
+
+// isNativeAOT reports whether the ELF file at path holds code compiled by
+// the native AOT compiler. A file whose sections can not be read is not.
+func isNativeAOT(path string) (bool, error) {
+	file, err := elf.Open(path)
+	if err != nil {
+		var formatErr *elf.FormatError
+		if errors.As(err, &formatErr) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	for _, section := range file.Sections {
+		if slices.Contains(nativeAOTSections, section.Name) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
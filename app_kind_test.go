@@ -0,0 +1,266 @@
+package dotnetexecute_test
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dotnetexecute "github.com/paketo-buildpacks/dotnet-execute"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+// writeExecutable writes an ELF executable stand-in that holds empty
+// sections with the given names.
+func writeExecutable(path string, sectionNames ...string) error {
+	shstrtab := []byte{0}
+	var names []uint32
+	for _, name := range append([]string{".shstrtab"}, sectionNames...) {
+		names = append(names, uint32(len(shstrtab)))
+		shstrtab = append(append(shstrtab, name...), 0)
+	}
+
+	const headerSize, sectionSize = 64, 64
+	shstrtabOffset := uint64(headerSize)
+	sectionsOffset := shstrtabOffset + uint64(len(shstrtab))
+
+	header := elf.Header64{
+		Type:      uint16(elf.ET_EXEC),
+		Machine:   uint16(elf.EM_X86_64),
+		Version:   uint32(elf.EV_CURRENT),
+		Shoff:     sectionsOffset,
+		Ehsize:    headerSize,
+		Shentsize: sectionSize,
+		Shnum:     uint16(len(names) + 1),
+		Shstrndx:  1,
+	}
+	copy(header.Ident[:], elf.ELFMAG)
+	header.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	header.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	header.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+
+	sections := []elf.Section64{
+		{},
+		{Name: names[0], Type: uint32(elf.SHT_STRTAB), Off: shstrtabOffset, Size: uint64(len(shstrtab)), Addralign: 1},
+	}
+	for _, name := range names[1:] {
+		sections = append(sections, elf.Section64{Name: name, Type: uint32(elf.SHT_PROGBITS), Off: sectionsOffset, Addralign: 1})
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	for _, data := range []any{header, shstrtab, sections} {
+		err := binary.Write(buffer, binary.LittleEndian, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return os.WriteFile(path, buffer.Bytes(), 0755)
+}
+
+func testAppKind(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		appDir string
+	)
+
+	it.Before(func() {
+		var err error
+		appDir, err = os.MkdirTemp("", "app")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(appDir)).To(Succeed())
+	})
+
+	context("ClassifyApp", func() {
+		it("classifies a framework-dependent deployment", func() {
+			kind, err := dotnetexecute.ClassifyApp(dotnetexecute.RuntimeConfig{
+				Path:           filepath.Join(appDir, "myapp.runtimeconfig.json"),
+				AppName:        "myapp",
+				RuntimeVersion: "8.0.0",
+			}, appDir, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(kind).To(Equal(dotnetexecute.AppKindFrameworkDependentDeployment))
+		})
+
+		it("classifies a framework-dependent executable", func() {
+			Expect(writeExecutable(filepath.Join(appDir, "myapp"), ".text")).To(Succeed())
+
+			kind, err := dotnetexecute.ClassifyApp(dotnetexecute.RuntimeConfig{
+				Path:           filepath.Join(appDir, "myapp.runtimeconfig.json"),
+				AppName:        "myapp",
+				RuntimeVersion: "8.0.0",
+				Executable:     true,
+			}, appDir, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(kind).To(Equal(dotnetexecute.AppKindFrameworkDependentExecutable))
+		})
+
+		it("classifies a self-contained app", func() {
+			kind, err := dotnetexecute.ClassifyApp(dotnetexecute.RuntimeConfig{
+				Path:       filepath.Join(appDir, "myapp.runtimeconfig.json"),
+				AppName:    "myapp",
+				Executable: true,
+			}, appDir, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(kind).To(Equal(dotnetexecute.AppKindSelfContained))
+		})
+
+		it("classifies a single-file bundle", func() {
+			Expect(writeSingleFileBundle(filepath.Join(appDir, "myapp"), 6, 0, bundleFile{Type: 1, Name: "myapp.dll"})).To(Succeed())
+
+			kind, err := dotnetexecute.ClassifyApp(dotnetexecute.RuntimeConfig{
+				AppName:        "myapp",
+				RuntimeVersion: "8.0.0",
+				Executable:     true,
+			}, appDir, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(kind).To(Equal(dotnetexecute.AppKindSingleFile))
+		})
+
+		it("classifies a native AOT app", func() {
+			Expect(writeExecutable(filepath.Join(appDir, "myapp"), ".text", "__managedcode")).To(Succeed())
+
+			kind, err := dotnetexecute.ClassifyApp(dotnetexecute.RuntimeConfig{
+				AppName:    "myapp",
+				Executable: true,
+			}, appDir, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(kind).To(Equal(dotnetexecute.AppKindNativeAOT))
+		})
+
+		it("classifies an app with a project file as a source app", func() {
+			kind, err := dotnetexecute.ClassifyApp(dotnetexecute.RuntimeConfig{
+				Path:           filepath.Join(appDir, "myapp.runtimeconfig.json"),
+				AppName:        "myapp",
+				RuntimeVersion: "8.0.0",
+			}, appDir, "/path/to/myapp.csproj")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(kind).To(Equal(dotnetexecute.AppKindSource))
+		})
+
+		context("when there is no app", func() {
+			it("returns no kind", func() {
+				kind, err := dotnetexecute.ClassifyApp(dotnetexecute.RuntimeConfig{}, appDir, "")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(kind).To(BeEmpty())
+			})
+		})
+	})
+
+	context("FindExecutableApp", func() {
+		it("finds a single-file bundle and reads its runtimeconfig.json", func() {
+			Expect(writeSingleFileBundle(filepath.Join(appDir, "myapp"), 6, 0,
+				bundleFile{Type: 1, Name: "myapp.dll"},
+				bundleFile{Type: 4, Name: "myapp.runtimeconfig.json", Content: `{
+					"runtimeOptions": {
+						"frameworks": [
+							{"name": "Microsoft.NETCore.App", "version": "8.0.0"},
+							{"name": "Microsoft.AspNetCore.App", "version": "8.0.0"}
+						]
+					}
+				}`},
+			)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(appDir, "appsettings.json"), []byte("{}"), 0600)).To(Succeed())
+
+			app, ok, err := dotnetexecute.FindExecutableApp(appDir, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(app).To(Equal(dotnetexecute.RuntimeConfig{
+				AppName:        "myapp",
+				RuntimeVersion: "8.0.0",
+				ASPNETVersion:  "8.0.0",
+				Executable:     true,
+			}))
+		})
+
+		it("finds a native AOT app", func() {
+			Expect(writeExecutable(filepath.Join(appDir, "myapp"), "__managedcode")).To(Succeed())
+			Expect(writeSharedLibrary(filepath.Join(appDir, "libNative.so"))).To(Succeed())
+
+			app, ok, err := dotnetexecute.FindExecutableApp(appDir, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(app).To(Equal(dotnetexecute.RuntimeConfig{
+				AppName:    "myapp",
+				Executable: true,
+			}))
+		})
+
+		context("when the entry assembly is set", func() {
+			it.Before(func() {
+				Expect(writeExecutable(filepath.Join(appDir, "myapp"), "__managedcode")).To(Succeed())
+				Expect(writeExecutable(filepath.Join(appDir, "mytool"), "__managedcode")).To(Succeed())
+			})
+
+			it("only considers that file", func() {
+				app, ok, err := dotnetexecute.FindExecutableApp(appDir, "mytool")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ok).To(BeTrue())
+				Expect(app.AppName).To(Equal("mytool"))
+			})
+
+			context("when it is a DLL", func() {
+				it("reports false", func() {
+					_, ok, err := dotnetexecute.FindExecutableApp(appDir, "mytool.dll")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(ok).To(BeFalse())
+				})
+			})
+		})
+
+		context("when there is only an ELF executable that is not a .NET app", func() {
+			it.Before(func() {
+				Expect(writeExecutable(filepath.Join(appDir, "helper"), ".text")).To(Succeed())
+			})
+
+			it("reports false", func() {
+				_, ok, err := dotnetexecute.FindExecutableApp(appDir, "")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ok).To(BeFalse())
+			})
+		})
+
+		context("when the directory does not exist", func() {
+			it("reports false", func() {
+				_, ok, err := dotnetexecute.FindExecutableApp(filepath.Join(appDir, "missing"), "")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ok).To(BeFalse())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when there are several app executables", func() {
+				it.Before(func() {
+					Expect(writeExecutable(filepath.Join(appDir, "myapp"), "__managedcode")).To(Succeed())
+					Expect(writeExecutable(filepath.Join(appDir, "mytool"), "__managedcode")).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, _, err := dotnetexecute.FindExecutableApp(appDir, "")
+					Expect(err).To(MatchError(ContainSubstring("found multiple app executables in %s, set BP_DOTNET_ENTRY_ASSEMBLY to choose one of: myapp, mytool", appDir)))
+				})
+			})
+
+			context("when the bundled runtimeconfig.json is malformed", func() {
+				it.Before(func() {
+					Expect(writeSingleFileBundle(filepath.Join(appDir, "myapp"), 6, 0,
+						bundleFile{Type: 4, Name: "myapp.runtimeconfig.json", Content: "%%%"},
+					)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, _, err := dotnetexecute.FindExecutableApp(appDir, "")
+					Expect(err).To(MatchError(ContainSubstring("failed to decode the bundled runtimeconfig.json of %s", filepath.Join(appDir, "myapp"))))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,71 @@
+package dotnetexecute
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ParseArgs splits a command line into arguments the way a POSIX shell
+// would, honouring single quotes, double quotes and backslash escapes. It
+// does not perform any expansion.
+func ParseArgs(line string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			if quote == '"' && r != '"' && r != '\\' && r != '$' && r != '`' {
+				current.WriteRune('\\')
+			}
+			current.WriteRune(r)
+			escaped = false
+
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+
+		case quote != 0:
+			if r == quote {
+				quote = 0
+				continue
+			}
+			current.WriteRune(r)
+
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if escaped {
+		return nil, fmt.Errorf("failed to parse arguments %q: trailing backslash", line)
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("failed to parse arguments %q: unterminated %c quote", line, quote)
+	}
+
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
@@ -0,0 +1,49 @@
+package dotnetexecute_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	dotnetexecute "github.com/paketo-buildpacks/dotnet-execute"
+	"github.com/sclevine/spec"
+)
+
+func testParseArgs(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	it("splits arguments on whitespace", func() {
+		args, err := dotnetexecute.ParseArgs("  --urls http://0.0.0.0:8080\t--contentRoot /workspace ")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(args).To(Equal([]string{"--urls", "http://0.0.0.0:8080", "--contentRoot", "/workspace"}))
+	})
+
+	it("returns no arguments for an empty line", func() {
+		args, err := dotnetexecute.ParseArgs("   ")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(args).To(BeEmpty())
+	})
+
+	it("keeps quoted whitespace and empty quoted arguments", func() {
+		args, err := dotnetexecute.ParseArgs(`seed --name 'some name' --greeting "hello world" ''`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(args).To(Equal([]string{"seed", "--name", "some name", "--greeting", "hello world", ""}))
+	})
+
+	it("handles escapes the way a shell would", func() {
+		args, err := dotnetexecute.ParseArgs(`a\ b "c\"d\e" 'f\g' h"i"'j'`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(args).To(Equal([]string{"a b", `c"d\e`, `f\g`, "hij"}))
+	})
+
+	context("failure cases", func() {
+		it("errors on an unterminated quote", func() {
+			_, err := dotnetexecute.ParseArgs(`--name 'oops`)
+			Expect(err).To(MatchError(ContainSubstring("unterminated ' quote")))
+		})
+
+		it("errors on a trailing backslash", func() {
+			_, err := dotnetexecute.ParseArgs(`--name oops\`)
+			Expect(err).To(MatchError(ContainSubstring("trailing backslash")))
+		})
+	})
+}
@@ -1,19 +1,23 @@
 package dotnetexecute
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
-	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/Netflix/go-env"
 	"github.com/paketo-buildpacks/packit/v2"
 	"github.com/paketo-buildpacks/packit/v2/chronos"
+	"github.com/paketo-buildpacks/packit/v2/fs"
 	"github.com/paketo-buildpacks/packit/v2/sbom"
 	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/paketo-buildpacks/packit/v2/servicebindings"
 )
 
 //go:generate faux --interface SBOMGenerator --output fakes/sbom_generator.go
@@ -21,15 +25,27 @@ type SBOMGenerator interface {
 	Generate(path string) (sbom.SBOM, error)
 }
 
+//go:generate faux --interface ProcessParser --output fakes/process_parser.go
+type ProcessParser interface {
+	Parse(path string) ([]ProcessDefinition, error)
+}
+
 // Build will return a packit.BuildFunc that will be invoked during the build
 // phase of the buildpack lifecycle.
 //
 // Build generates a SBOM of the .NET app's dependencies based on its compiled
 // DLLs. It sets up the entrypoint for the app image and adds a helper that
 // will determine at launch-time which container port the app should listen on.
+// A launch-helpers layer holds the other launch-time helpers, which handle
+// crash dumps, the diagnostic port, data protection keys and read-only root
+// filesystems. See Configuration for the settings that drive the rest of the
+// build, such as extra processes, EF Core migrations, startup hooks, image
+// labels and the build report.
 func Build(
 	config Configuration,
 	configParser ConfigParser,
+	projectParser ProjectParser,
+	processParser ProcessParser,
 	sbomGenerator SBOMGenerator,
 	logger scribe.Emitter,
 	clock chronos.Clock,
@@ -52,9 +68,58 @@ func Build(
 		}
 		logger.Debug.Break()
 
-		runtimeConfig, err := configParser.Parse(filepath.Join(context.WorkingDir, "*.runtimeconfig.json"))
+		sourceReload := config.LiveReloadEnabled && config.LiveReloadMode == LiveReloadModeSource
+
+		runArgs, err := ParseArgs(config.RunArgs)
 		if err != nil {
-			return packit.BuildResult{}, fmt.Errorf("failed to find *.runtimeconfig.json: %w", err)
+			return packit.BuildResult{}, err
+		}
+
+		extraLabels, err := ParseImageLabels(config.ImageLabels)
+		if err != nil {
+			return packit.BuildResult{}, fmt.Errorf("failed to parse BP_DOTNET_IMAGE_LABELS: %w", err)
+		}
+
+		// The report from Detect, when it is on the same filesystem, holds
+		// the build plan requirements and the reasons for them.
+		var report Report
+		reportFile := reportPath(config, context.WorkingDir)
+		if reportFile != "" {
+			report, err = ReadReport(reportFile)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+		}
+		if report.Files == nil {
+			report.Files = map[string]string{}
+		}
+		if len(report.Requirements) == 0 {
+			report.Requirements = reportPlanRequirements(context.Plan)
+		}
+
+		var runtimeConfig RuntimeConfig
+		if !sourceReload {
+			runtimeConfig, err = configParser.Parse(runtimeConfigGlob(config, context.WorkingDir))
+
+			// Single-file bundles and native AOT executables are published
+			// without a runtimeconfig.json next to them.
+			if errors.Is(err, os.ErrNotExist) {
+				executableApp, found, findErr := FindExecutableApp(context.WorkingDir, config.EntryAssembly)
+				if findErr != nil {
+					return packit.BuildResult{}, findErr
+				}
+
+				if found {
+					runtimeConfig, err = executableApp, nil
+				}
+			}
+
+			if err != nil {
+				if config.EntryAssembly != "" {
+					return packit.BuildResult{}, fmt.Errorf("failed to find runtimeconfig.json for entry assembly %s: %w", config.EntryAssembly, err)
+				}
+				return packit.BuildResult{}, fmt.Errorf("failed to find *.runtimeconfig.json: %w", err)
+			}
 		}
 
 		logger.GeneratingSBOM(context.WorkingDir)
@@ -76,69 +141,469 @@ func Build(
 			return packit.BuildResult{}, err
 		}
 
-		command := filepath.Join(context.WorkingDir, runtimeConfig.AppName)
-		var args []string
-		if !runtimeConfig.Executable {
-			_, err := os.Stat(filepath.Join(context.WorkingDir, fmt.Sprintf("%s.dll", runtimeConfig.AppName)))
-			if err != nil && !errors.Is(err, os.ErrNotExist) {
+		var (
+			processes         []packit.Process
+			reloadPatterns    LiveReloadPatterns
+			depsPath          string
+			packageReferences []string
+			imageMetadata     ImageMetadata
+			kind              AppKind
+		)
+
+		if sourceReload {
+			projectDir := context.WorkingDir
+			if config.ProjectPath != "" {
+				projectDir = filepath.Join(projectDir, config.ProjectPath)
+			}
+
+			project, err := projectParser.FindProjectFile(context.WorkingDir, projectDir, config.ProjectName)
+			if err != nil {
 				return packit.BuildResult{}, err
 			}
-			if errors.Is(err, os.ErrNotExist) {
-				return packit.BuildResult{}, fmt.Errorf("no entrypoint [%s.dll] found: %w ", runtimeConfig.AppName, err)
+			projectFile := project.Path
+
+			if projectFile == "" {
+				return packit.BuildResult{}, fmt.Errorf("no project file found in %s: BP_LIVE_RELOAD_MODE=%s requires one", projectDir, LiveReloadModeSource)
 			}
 
-			command = "dotnet"
-			args = append(args, fmt.Sprintf("%s.dll", filepath.Join(context.WorkingDir, runtimeConfig.AppName)))
-		}
+			kind = AppKindSource
+			report.Files["project_file"] = projectFile
 
-		processes := []packit.Process{
-			{
-				Type:    runtimeConfig.AppName,
-				Command: command,
-				Args:    args,
-				Default: true,
-				Direct:  true,
-			},
-		}
+			projectName := strings.TrimSuffix(filepath.Base(projectFile), filepath.Ext(projectFile))
+			watchArgs := []string{"watch", "run", "--project", projectFile}
+			if len(runArgs) > 0 {
+				watchArgs = append(append(watchArgs, "--"), runArgs...)
+			}
 
-		if config.LiveReloadEnabled {
 			processes = []packit.Process{
 				{
-					Type:    fmt.Sprintf("reload-%s", runtimeConfig.AppName),
-					Command: "watchexec",
-					Args: append([]string{
-						"--restart",
-						"--watch", context.WorkingDir,
-						"--shell", "none",
-						"--",
-						command,
-					}, args...),
+					Type:    fmt.Sprintf("reload-%s", projectName),
+					Command: "dotnet",
+					Args:    watchArgs,
 					Default: true,
 					Direct:  true,
 				},
+			}
+
+			// dotnet watch rebuilds on any change to the project's sources.
+			reloadPatterns = LiveReloadPatterns{Root: context.WorkingDir, Watch: []string{projectDir}}
+
+			packageReferences, err = projectParser.PackageReferences(context.WorkingDir, projectFile)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			if !config.DisableImageLabels {
+				imageMetadata, err = projectParser.ImageMetadata(context.WorkingDir, projectFile)
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+			}
+		} else {
+			appDir := filepath.Join(context.WorkingDir, filepath.Dir(config.EntryAssembly))
+			useDLL := !runtimeConfig.Executable
+			if config.EntryAssembly != "" {
+				useDLL = strings.HasSuffix(config.EntryAssembly, ".dll")
+				if !useDLL && !runtimeConfig.Executable {
+					return packit.BuildResult{}, fmt.Errorf("entry assembly %s is not an executable", config.EntryAssembly)
+				}
+			}
+
+			command := filepath.Join(appDir, runtimeConfig.AppName)
+			var args []string
+			if useDLL {
+				_, err := os.Stat(filepath.Join(appDir, fmt.Sprintf("%s.dll", runtimeConfig.AppName)))
+				if err != nil && !errors.Is(err, os.ErrNotExist) {
+					return packit.BuildResult{}, err
+				}
+				if errors.Is(err, os.ErrNotExist) {
+					return packit.BuildResult{}, fmt.Errorf("no entrypoint [%s.dll] found: %w ", runtimeConfig.AppName, err)
+				}
+
+				command = "dotnet"
+				args = append(args, fmt.Sprintf("%s.dll", filepath.Join(appDir, runtimeConfig.AppName)))
+			}
+			args = append(args, runArgs...)
+
+			kind, err = ClassifyApp(runtimeConfig, appDir, "")
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			if runtimeConfig.Path != "" {
+				report.Files["runtime_config"] = runtimeConfig.Path
+			}
+			report.Files["entrypoint"] = command
+			if useDLL {
+				report.Files["entrypoint"] = args[0]
+			}
+
+			processes = []packit.Process{
 				{
 					Type:    runtimeConfig.AppName,
 					Command: command,
 					Args:    args,
+					Default: true,
 					Direct:  true,
 				},
 			}
 
-			err := filepath.Walk(context.WorkingDir, func(path string, info fs.FileInfo, err error) error {
+			if config.LiveReloadEnabled {
+				reloadPatterns = NewLiveReloadPatterns(config, context.WorkingDir)
+
+				watchexecArgs := []string{"--restart"}
+				watchexecArgs = append(watchexecArgs, reloadPatterns.Args()...)
+				watchexecArgs = append(watchexecArgs, "--shell", "none", "--", command)
+
+				processes = []packit.Process{
+					{
+						Type:    fmt.Sprintf("reload-%s", runtimeConfig.AppName),
+						Command: "watchexec",
+						Args:    append(watchexecArgs, args...),
+						Default: true,
+						Direct:  true,
+					},
+					{
+						Type:    runtimeConfig.AppName,
+						Command: command,
+						Args:    args,
+						Direct:  true,
+					},
+				}
+			}
+
+			if !useDLL {
+				bundle, isBundle, err := ReadSingleFileBundle(command)
+				if err != nil && !errors.Is(err, os.ErrNotExist) {
+					return packit.BuildResult{}, err
+				}
+
+				if isBundle && len(bundle.Extracted) > 0 {
+					logger.Process("Single-file app %s extracts %d files at startup", bundle.Path, len(bundle.Extracted))
+					for _, name := range bundle.Extracted {
+						logger.Debug.Subprocess("%s", name)
+					}
+					logger.Subprocess("They are written to DOTNET_BUNDLE_EXTRACT_BASE_DIR or TMPDIR, which must be writable")
+					logger.Subprocess("With a read-only root filesystem, set BPL_DOTNET_READONLY_ROOTFS=true and mount a writable volume")
+					logger.Break()
+				}
+			}
+
+			depsPath = filepath.Join(appDir, fmt.Sprintf("%s.deps.json", runtimeConfig.AppName))
+			report.Files["deps_json"] = depsPath
+
+			if !config.DisableImageLabels {
+				imageMetadata, err = ReadAssemblyMetadata(filepath.Join(appDir, fmt.Sprintf("%s.dll", runtimeConfig.AppName)))
 				if err != nil {
-					return err
+					return packit.BuildResult{}, err
 				}
-				if path == context.WorkingDir {
-					return nil
+
+				if imageMetadata.Title == "" {
+					imageMetadata.Title = runtimeConfig.AppName
+				}
+			}
+
+			searchPaths := append(filepath.SplitList(os.Getenv("LD_LIBRARY_PATH")), DefaultLibraryPaths...)
+			unresolved, err := FindUnresolvedLibraries(appDir, searchPaths)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			if len(unresolved) > 0 {
+				var names []string
+				for _, library := range unresolved {
+					names = append(names, library.Name)
 				}
 
-				return os.Chmod(path, info.Mode()|0060)
-			})
+				if config.StrictNativeLibraries {
+					return packit.BuildResult{}, fmt.Errorf("native libraries in the app need shared libraries that were not found: %s", strings.Join(names, ", "))
+				}
+
+				logger.Process("Warning: native libraries in the app need shared libraries that were not found")
+				for _, library := range unresolved {
+					logger.Subprocess("%s, needed by %s", library.Name, strings.Join(library.NeededBy, ", "))
+				}
+				logger.Subprocess("The app may fail at startup unless the run image includes them")
+				logger.Break()
+			}
+
+			problems, err := ValidatePublishOutput(appDir, runtimeConfig, kind)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			if len(problems) > 0 {
+				logger.Process("Warning: the publish output in %s is incomplete or inconsistent", appDir)
+				for _, problem := range problems {
+					logger.Subprocess("%s", problem)
+				}
+				logger.Subprocess("The app may fail at startup with a FileNotFoundException; check that the whole publish output was copied")
+				logger.Break()
+			}
+			report.PublishProblems = problems
+		}
+
+		libraries, err := FindNativeLibraries(depsPath, packageReferences)
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		// The libraries are required from other buildpacks, so they never
+		// show up in this buildpack's plan, and Build can not tell whether a
+		// buildpack provided them.
+		if len(libraries) > 0 {
+			logger.Process("The app needs system libraries that are not part of every run image")
+			for _, library := range libraries {
+				logger.Subprocess("%s, needed by %s", library.Name, strings.Join(library.Sources, ", "))
+			}
+			logger.Subprocess("The app may fail at runtime unless a buildpack provides them or the run image includes them")
+			logger.Break()
+		}
+
+		definitions, err := ParseProcessDefinitions(config.Processes)
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		if config.Processes == "" {
+			definitions, err = processParser.Parse(filepath.Join(context.WorkingDir, ProcessDescriptorFile))
 			if err != nil {
 				return packit.BuildResult{}, err
 			}
 		}
 
+		var defaults int
+		for _, definition := range definitions {
+			process, err := definition.Process(context.WorkingDir, kind)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			if hasProcessType(processes, process.Type) {
+				return packit.BuildResult{}, fmt.Errorf("process type %q is already defined", process.Type)
+			}
+
+			if process.Default {
+				defaults++
+				if defaults > 1 {
+					return packit.BuildResult{}, errors.New("more than one additional process is marked as default")
+				}
+
+				for i := range processes {
+					processes[i].Default = false
+				}
+			}
+
+			processes = append(processes, process)
+		}
+
+		var layers []packit.Layer
+		if !sourceReload && !hasProcessType(processes, "migrate") {
+			bundle, found, err := FindEFBundle(context.WorkingDir, config.EFBundlePath)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			if found {
+				mode := "framework-dependent"
+				if bundle.SelfContained {
+					mode = "self-contained"
+				}
+
+				logger.Process("Found %s EF Core migration bundle %s", mode, bundle.Path)
+				logger.Break()
+
+				if !bundle.SelfContained && runtimeConfig.RuntimeVersion == "" {
+					return packit.BuildResult{}, fmt.Errorf("EF Core migration bundle %s is framework-dependent, but the app is self-contained and no shared .NET runtime will be present: publish the bundle with --self-contained", bundle.Path)
+				}
+
+				efMigrateLayer, err := context.Layers.Get("ef-migrate")
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+
+				efMigrateLayer, err = efMigrateLayer.Reset()
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+				efMigrateLayer.Launch = true
+
+				err = os.MkdirAll(filepath.Join(efMigrateLayer.Path, "bin"), os.ModePerm)
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+
+				efMigrate := filepath.Join(efMigrateLayer.Path, "bin", "ef-migrate")
+				err = fs.Copy(filepath.Join(context.CNBPath, "bin", "ef-migrate"), efMigrate)
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+
+				connectionEnv := config.EFConnectionEnv
+				if connectionEnv == "" {
+					connectionEnv = DefaultEFConnectionEnv
+				}
+
+				processes = append(processes, packit.Process{
+					Type:    "migrate",
+					Command: efMigrate,
+					Args:    []string{"--connection-env", connectionEnv, "--", bundle.Path},
+					Direct:  true,
+				})
+				layers = append(layers, efMigrateLayer)
+			}
+		}
+
+		if config.OTelAutoInstrumentation {
+			home := os.Getenv("OTEL_DOTNET_AUTO_HOME")
+			if config.OTelAutoInstrumentationPath != "" {
+				home = filepath.Join(context.WorkingDir, config.OTelAutoInstrumentationPath)
+			}
+
+			if home == "" {
+				return packit.BuildResult{}, errors.New("BP_DOTNET_OTEL_AUTO_INSTRUMENTATION is set, but neither BP_DOTNET_OTEL_AUTO_INSTRUMENTATION_PATH nor OTEL_DOTNET_AUTO_HOME points to the instrumentation")
+			}
+
+			instrumentation, err := FindOTelAutoInstrumentation(home, runtime.GOARCH)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			otelLayer, err := context.Layers.Get("otel-auto-instrumentation")
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			otelLayer, err = otelLayer.Reset()
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+			otelLayer.Launch = true
+
+			otelLayer.LaunchEnv.Override("CORECLR_ENABLE_PROFILING", "1")
+			otelLayer.LaunchEnv.Override("CORECLR_PROFILER", OTelProfilerID)
+			otelLayer.LaunchEnv.Override("CORECLR_PROFILER_PATH", instrumentation.ProfilerPath)
+			otelLayer.LaunchEnv.Append("DOTNET_STARTUP_HOOKS", instrumentation.StartupHook, ":")
+			otelLayer.LaunchEnv.Append("DOTNET_ADDITIONAL_DEPS", instrumentation.AdditionalDeps, ":")
+			otelLayer.LaunchEnv.Append("DOTNET_SHARED_STORE", instrumentation.SharedStore, ":")
+			otelLayer.LaunchEnv.Override("OTEL_DOTNET_AUTO_HOME", instrumentation.Home)
+
+			// Exporter settings such as OTEL_EXPORTER_OTLP_ENDPOINT can come
+			// from an "opentelemetry" service binding at launch.
+			otelLayer.ExecD = []string{filepath.Join(context.CNBPath, "bin", "otel-bindings")}
+
+			logger.Process("Configuring OpenTelemetry .NET automatic instrumentation from %s", instrumentation.Home)
+			logger.LayerFlags(otelLayer)
+			logger.EnvironmentVariables(otelLayer)
+
+			layers = append(layers, otelLayer)
+		}
+
+		var hooks []string
+		for _, path := range splitList(config.StartupHooks) {
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(context.WorkingDir, path)
+			}
+			hooks = append(hooks, path)
+		}
+
+		layerHooks, err := FindLayerStartupHooks(context.Layers.Path)
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+		hooks = append(hooks, layerHooks...)
+
+		hookBindings, err := servicebindings.NewResolver().Resolve(StartupHookBindingType, "", context.Platform.Path)
+		if err != nil {
+			return packit.BuildResult{}, fmt.Errorf("failed to resolve %q service bindings: %w", StartupHookBindingType, err)
+		}
+
+		if len(hooks) > 0 || len(hookBindings) > 0 {
+			hooksLayer, err := context.Layers.Get("startup-hooks")
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			hooksLayer, err = hooksLayer.Reset()
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+			hooksLayer.Launch = true
+
+			// Binding entries only exist at build time, so the assemblies are
+			// copied into the layer.
+			for _, binding := range hookBindings {
+				var names []string
+				for name := range binding.Entries {
+					if strings.HasSuffix(name, ".dll") {
+						names = append(names, name)
+					}
+				}
+				sort.Strings(names)
+
+				for _, name := range names {
+					content, err := binding.Entries[name].ReadBytes()
+					if err != nil {
+						return packit.BuildResult{}, err
+					}
+
+					path := filepath.Join(hooksLayer.Path, binding.Name, name)
+					err = os.MkdirAll(filepath.Dir(path), os.ModePerm)
+					if err != nil {
+						return packit.BuildResult{}, err
+					}
+
+					err = os.WriteFile(path, content, 0644)
+					if err != nil {
+						return packit.BuildResult{}, err
+					}
+					hooks = append(hooks, path)
+				}
+			}
+
+			if len(hooks) > 0 {
+				logger.Process("Adding startup hooks")
+				for _, path := range hooks {
+					hook, err := ReadStartupHook(path, runtimeConfig.RuntimeVersion)
+					if err != nil {
+						return packit.BuildResult{}, err
+					}
+
+					if hook.TargetFramework != "" {
+						logger.Subprocess("%s (%s)", hook.Path, hook.TargetFramework)
+					} else {
+						logger.Subprocess("%s", hook.Path)
+					}
+				}
+				logger.Break()
+
+				// Appending keeps any hooks that are set when the app runs.
+				hooksLayer.LaunchEnv.Append("DOTNET_STARTUP_HOOKS", strings.Join(hooks, ":"), ":")
+				logger.EnvironmentVariables(hooksLayer)
+
+				layers = append(layers, hooksLayer)
+			}
+		}
+
+		if config.LiveReloadEnabled {
+			var writablePaths []string
+			for _, path := range splitList(config.LiveReloadWritablePaths) {
+				writablePaths = append(writablePaths, filepath.Join(context.WorkingDir, path))
+			}
+
+			summary, err := GrantGroupReadWrite(reloadPatterns, writablePaths)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			logger.Process("Granting group read/write access for live reload")
+			logger.Subprocess("Updated %d files and %d directories", summary.Files, summary.Directories)
+			for _, path := range summary.Skipped {
+				logger.Subprocess("Skipped %s: resolves outside of the app root", path)
+			}
+			logger.Break()
+		}
+
 		logger.LaunchProcesses(processes)
 
 		portChooserLayer, err := context.Layers.Get("port-chooser")
@@ -152,17 +617,140 @@ func Build(
 			portChooserLayer.LaunchEnv.Default("ASPNETCORE_ENVIRONMENT", "Development")
 		}
 
+		if sourceReload {
+			// File sync tools such as Tilt or Skaffold do not always produce
+			// inotify events inside the container, and nobody is around to
+			// answer dotnet watch's interactive prompts.
+			portChooserLayer.LaunchEnv.Default("DOTNET_USE_POLLING_FILE_WATCHER", "true")
+			portChooserLayer.LaunchEnv.Default("DOTNET_WATCH_RESTART_ON_RUDE_EDIT", "true")
+			portChooserLayer.LaunchEnv.Default("DOTNET_WATCH_SUPPRESS_LAUNCH_BROWSER", "true")
+		}
+
 		logger.LayerFlags(portChooserLayer)
 		logger.EnvironmentVariables(portChooserLayer)
 
+		launchHelpersLayer, err := context.Layers.Get("launch-helpers")
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+		launchHelpersLayer.Launch = true
+		launchHelpersLayer.ExecD = []string{
+			filepath.Join(context.CNBPath, "bin", "crash-dumps"),
+			filepath.Join(context.CNBPath, "bin", "diagnostic-port"),
+			// data-protection runs first so that its key path wins over the
+			// scratch location that readonly-rootfs falls back to.
+			filepath.Join(context.CNBPath, "bin", "data-protection"),
+			filepath.Join(context.CNBPath, "bin", "readonly-rootfs"),
+		}
+
+		logger.LayerFlags(launchHelpersLayer)
+
+		// The app kind says how the app came to the build, as Detect saw it.
+		// An app that was built from its project file is a source app, even
+		// though it runs from the publish output.
+		if planAppKind(context.Plan) == AppKindSource {
+			kind = AppKindSource
+		}
+
+		// Later buildpacks, such as APM agents, and the app itself can branch
+		// on the app kind.
+		appKindLayer, err := context.Layers.Get("app-kind")
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		appKindLayer, err = appKindLayer.Reset()
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+		appKindLayer.Build = true
+		appKindLayer.Launch = true
+		appKindLayer.SharedEnv.Override(AppKindEnv, string(kind))
+
+		logger.Process("App kind is %s", kind)
+		logger.LayerFlags(appKindLayer)
+		logger.EnvironmentVariables(appKindLayer)
+
+		layers = append(layers, appKindLayer)
+
+		labels := imageMetadata.Labels()
+		for key, value := range extraLabels {
+			labels[key] = value
+		}
+
+		if len(labels) > 0 {
+			var keys []string
+			for key := range labels {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			logger.Process("Setting image labels")
+			for _, key := range keys {
+				logger.Subprocess("%s: %s", key, labels[key])
+			}
+			logger.Break()
+		}
+
+		layers = append([]packit.Layer{portChooserLayer, launchHelpersLayer}, layers...)
+
+		report.AppKind = kind
+		report.Processes = NewReportProcesses(processes)
+		report.LaunchEnv = reportLaunchEnv(layers)
+
+		// Anyone who can pull the image can read its labels, and launch
+		// environment values can hold settings such as connection strings.
+		labelReport := report
+		labelReport.LaunchEnv = nil
+
+		content, err := json.Marshal(labelReport)
+		if err != nil {
+			// not tested
+			return packit.BuildResult{}, err
+		}
+		labels[ReportLabel] = string(content)
+
+		if reportFile != "" {
+			logger.Process("Writing report to %s", reportFile)
+			logger.Break()
+
+			err = report.Write(reportFile)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+		}
+
 		return packit.BuildResult{
-			Layers: []packit.Layer{
-				portChooserLayer,
-			},
+			Layers: layers,
 			Launch: packit.LaunchMetadata{
 				Processes: processes,
 				SBOM:      sbomFormatter,
+				Labels:    labels,
 			},
 		}, nil
 	}
 }
+
+func hasProcessType(processes []packit.Process, processType string) bool {
+	for _, process := range processes {
+		if process.Type == processType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// planAppKind returns the app kind that Detect put in the metadata of the
+// dotnet-app-kind entry of plan.
+func planAppKind(plan packit.BuildpackPlan) AppKind {
+	for _, entry := range plan.Entries {
+		if entry.Name == AppKindPlanEntry {
+			if kind, ok := entry.Metadata["app-kind"].(string); ok {
+				return AppKind(kind)
+			}
+		}
+	}
+
+	return ""
+}
@@ -2,12 +2,15 @@ package dotnetexecute_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
 
 	dotnetexecute "github.com/paketo-buildpacks/dotnet-execute"
@@ -30,6 +33,8 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		configParser  *fakes.ConfigParser
 		layersDir     string
 		logger        scribe.Emitter
+		projectParser *fakes.ProjectParser
+		processParser *fakes.ProcessParser
 		sbomGenerator *fakes.SBOMGenerator
 		workingDir    string
 
@@ -48,6 +53,8 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		Expect(err).NotTo(HaveOccurred())
 
 		configParser = &fakes.ConfigParser{}
+		projectParser = &fakes.ProjectParser{}
+		processParser = &fakes.ProcessParser{}
 
 		sbomGenerator = &fakes.SBOMGenerator{}
 		sbomGenerator.GenerateCall.Returns.SBOM = sbom.SBOM{}
@@ -55,7 +62,7 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		buffer = bytes.NewBuffer(nil)
 		logger = scribe.NewEmitter(buffer)
 
-		build = dotnetexecute.Build(dotnetexecute.Configuration{}, configParser, sbomGenerator, logger, chronos.DefaultClock)
+		build = dotnetexecute.Build(dotnetexecute.Configuration{}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
 	})
 
 	it.After(func() {
@@ -90,7 +97,7 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			})
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(result.Layers).To(HaveLen(1))
+			Expect(result.Layers).To(HaveLen(3))
 			portLayer := result.Layers[0]
 
 			Expect(portLayer.Name).To(Equal("port-chooser"))
@@ -101,6 +108,26 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			Expect(portLayer.Launch).To(BeTrue())
 			Expect(portLayer.Cache).To(BeFalse())
 
+			launchHelpersLayer := result.Layers[1]
+			Expect(launchHelpersLayer.Name).To(Equal("launch-helpers"))
+			Expect(launchHelpersLayer.ExecD).To(Equal([]string{
+				filepath.Join(cnbDir, "bin", "crash-dumps"),
+				filepath.Join(cnbDir, "bin", "diagnostic-port"),
+				filepath.Join(cnbDir, "bin", "data-protection"),
+				filepath.Join(cnbDir, "bin", "readonly-rootfs"),
+			}))
+			Expect(launchHelpersLayer.Build).To(BeFalse())
+			Expect(launchHelpersLayer.Launch).To(BeTrue())
+			Expect(launchHelpersLayer.Cache).To(BeFalse())
+
+			appKindLayer := result.Layers[2]
+			Expect(appKindLayer.Name).To(Equal("app-kind"))
+			Expect(appKindLayer.SharedEnv).To(Equal(packit.Environment{
+				"BPI_DOTNET_APP_KIND.override": "self-contained",
+			}))
+			Expect(appKindLayer.Build).To(BeTrue())
+			Expect(appKindLayer.Launch).To(BeTrue())
+
 			Expect(result.Launch.SBOM.Formats()).To(HaveLen(2))
 			cdx := result.Launch.SBOM.Formats()[0]
 			spdx := result.Launch.SBOM.Formats()[1]
@@ -169,60 +196,1242 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 				"spdxVersion": "SPDX-2.2"
 			}`))
 
-			Expect(configParser.ParseCall.Receives.Glob).To(Equal(filepath.Join(workingDir, "*.runtimeconfig.json")))
+			Expect(configParser.ParseCall.Receives.Glob).To(Equal(filepath.Join(workingDir, "*.runtimeconfig.json")))
+
+			Expect(sbomGenerator.GenerateCall.Receives.Path).To(Equal(workingDir))
+		})
+	})
+
+	context("the app is a framework dependent deployment", func() {
+		it.Before(func() {
+			configParser.ParseCall.Returns.RuntimeConfig = dotnetexecute.RuntimeConfig{
+				Path:       filepath.Join(workingDir, "my.app.runtimeconfig.json"),
+				AppName:    "my.app",
+				Executable: false,
+			}
+			Expect(os.WriteFile(filepath.Join(workingDir, "my.app.dll"), nil, os.ModePerm)).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(filepath.Join(workingDir, "my.app.dll"))).To(Succeed())
+		})
+
+		it("returns a result that builds correctly", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:        "Some Buildpack",
+					Version:     "some-version",
+					SBOMFormats: []string{sbom.CycloneDXFormat, sbom.SPDXFormat},
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(3))
+			portLayer := result.Layers[0]
+
+			Expect(portLayer.Name).To(Equal("port-chooser"))
+			Expect(portLayer.Path).To(Equal(filepath.Join(layersDir, "port-chooser")))
+			Expect(portLayer.ExecD).To(Equal([]string{filepath.Join(cnbDir, "bin", "port-chooser")}))
+
+			Expect(result.Launch.Processes).To(Equal([]packit.Process{
+				{
+
+					Type:    "my.app",
+					Command: "dotnet",
+					Args:    []string{filepath.Join(workingDir, "my.app.dll")},
+					Default: true,
+					Direct:  true,
+				},
+			}))
+		})
+	})
+
+	context("when BP_DOTNET_RUN_ARGS is set", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "my.app.dll"), nil, os.ModePerm)).To(Succeed())
+
+			configParser.ParseCall.Returns.RuntimeConfig = dotnetexecute.RuntimeConfig{
+				Path:    filepath.Join(workingDir, "my.app.runtimeconfig.json"),
+				AppName: "my.app",
+			}
+
+			build = dotnetexecute.Build(dotnetexecute.Configuration{
+				RunArgs: `--urls http://0.0.0.0:9090 --contentRoot "/some dir"`,
+			}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
+		})
+
+		it("appends the arguments to the app process", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:        "Some Buildpack",
+					Version:     "some-version",
+					SBOMFormats: []string{sbom.CycloneDXFormat, sbom.SPDXFormat},
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Launch.Processes).To(Equal([]packit.Process{
+				{
+					Type:    "my.app",
+					Command: "dotnet",
+					Args: []string{
+						filepath.Join(workingDir, "my.app.dll"),
+						"--urls", "http://0.0.0.0:9090",
+						"--contentRoot", "/some dir",
+					},
+					Default: true,
+					Direct:  true,
+				},
+			}))
+		})
+	})
+
+	context("when BP_DOTNET_ENTRY_ASSEMBLY is set", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(workingDir, "sub"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "sub", "other.app.dll"), nil, os.ModePerm)).To(Succeed())
+
+			configParser.ParseCall.Returns.RuntimeConfig = dotnetexecute.RuntimeConfig{
+				Path:       filepath.Join(workingDir, "sub", "other.app.runtimeconfig.json"),
+				AppName:    "other.app",
+				Executable: true,
+			}
+		})
+
+		context("to a DLL", func() {
+			it.Before(func() {
+				build = dotnetexecute.Build(dotnetexecute.Configuration{
+					EntryAssembly: "sub/other.app.dll",
+				}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
+			})
+
+			it("runs that DLL with dotnet", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:        "Some Buildpack",
+						Version:     "some-version",
+						SBOMFormats: []string{sbom.CycloneDXFormat, sbom.SPDXFormat},
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(configParser.ParseCall.Receives.Glob).To(Equal(filepath.Join(workingDir, "sub", "other.app.runtimeconfig.json")))
+
+				Expect(result.Launch.Processes).To(Equal([]packit.Process{
+					{
+						Type:    "other.app",
+						Command: "dotnet",
+						Args:    []string{filepath.Join(workingDir, "sub", "other.app.dll")},
+						Default: true,
+						Direct:  true,
+					},
+				}))
+			})
+		})
+
+		context("to an executable", func() {
+			it.Before(func() {
+				build = dotnetexecute.Build(dotnetexecute.Configuration{
+					EntryAssembly: "sub/other.app",
+				}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
+			})
+
+			it("runs the executable", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:        "Some Buildpack",
+						Version:     "some-version",
+						SBOMFormats: []string{sbom.CycloneDXFormat, sbom.SPDXFormat},
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Launch.Processes).To(Equal([]packit.Process{
+					{
+						Type:    "other.app",
+						Command: filepath.Join(workingDir, "sub", "other.app"),
+						Default: true,
+						Direct:  true,
+					},
+				}))
+			})
+
+			context("when it is not executable", func() {
+				it.Before(func() {
+					configParser.ParseCall.Returns.RuntimeConfig.Executable = false
+				})
+
+				it("returns an error", func() {
+					_, err := build(packit.BuildContext{
+						WorkingDir: workingDir,
+						CNBPath:    cnbDir,
+						Stack:      "some-stack",
+						BuildpackInfo: packit.BuildpackInfo{
+							Name:    "Some Buildpack",
+							Version: "some-version",
+						},
+						Plan: packit.BuildpackPlan{
+							Entries: []packit.BuildpackPlanEntry{},
+						},
+						Layers: packit.Layers{Path: layersDir},
+					})
+					Expect(err).To(MatchError("entry assembly sub/other.app is not an executable"))
+				})
+			})
+		})
+
+		context("when it has no runtimeconfig.json", func() {
+			it.Before(func() {
+				configParser.ParseCall.Returns.Error = errors.New("no *.runtimeconfig.json found")
+
+				build = dotnetexecute.Build(dotnetexecute.Configuration{
+					EntryAssembly: "sub/missing.dll",
+				}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError("failed to find runtimeconfig.json for entry assembly sub/missing.dll: no *.runtimeconfig.json found"))
+			})
+		})
+	})
+
+	context("when additional processes are declared", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "my.app.dll"), nil, 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "migrator"), nil, 0755)).To(Succeed())
+
+			configParser.ParseCall.Returns.RuntimeConfig = dotnetexecute.RuntimeConfig{
+				Path:           filepath.Join(workingDir, "my.app.runtimeconfig.json"),
+				AppName:        "my.app",
+				RuntimeVersion: "8.0.0",
+			}
+
+			processParser.ParseCall.Returns.ProcessDefinitionSlice = []dotnetexecute.ProcessDefinition{
+				{Type: "migrate", Command: "migrator"},
+				{Type: "worker", Command: "my.app.dll", Args: []string{"worker"}, Default: true},
+			}
+		})
+
+		it("adds them next to the app process", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:        "Some Buildpack",
+					Version:     "some-version",
+					SBOMFormats: []string{sbom.CycloneDXFormat, sbom.SPDXFormat},
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(processParser.ParseCall.Receives.Path).To(Equal(filepath.Join(workingDir, "dotnet-processes.toml")))
+
+			Expect(result.Launch.Processes).To(Equal([]packit.Process{
+				{
+					Type:    "my.app",
+					Command: "dotnet",
+					Args:    []string{filepath.Join(workingDir, "my.app.dll")},
+					Direct:  true,
+				},
+				{
+					Type:    "migrate",
+					Command: filepath.Join(workingDir, "migrator"),
+					Direct:  true,
+				},
+				{
+					Type:    "worker",
+					Command: "dotnet",
+					Args:    []string{filepath.Join(workingDir, "my.app.dll"), "worker"},
+					Default: true,
+					Direct:  true,
+				},
+			}))
+		})
+
+		context("with BP_DOTNET_PROCESSES", func() {
+			it.Before(func() {
+				build = dotnetexecute.Build(dotnetexecute.Configuration{
+					Processes: "seed=my.app.dll seed --count 10",
+				}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
+			})
+
+			it("uses the setting instead of the descriptor file", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:        "Some Buildpack",
+						Version:     "some-version",
+						SBOMFormats: []string{sbom.CycloneDXFormat, sbom.SPDXFormat},
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(processParser.ParseCall.CallCount).To(Equal(0))
+
+				Expect(result.Launch.Processes).To(Equal([]packit.Process{
+					{
+						Type:    "my.app",
+						Command: "dotnet",
+						Args:    []string{filepath.Join(workingDir, "my.app.dll")},
+						Default: true,
+						Direct:  true,
+					},
+					{
+						Type:    "seed",
+						Command: "dotnet",
+						Args:    []string{filepath.Join(workingDir, "my.app.dll"), "seed", "--count", "10"},
+						Direct:  true,
+					},
+				}))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when a process type clashes with an existing one", func() {
+				it.Before(func() {
+					processParser.ParseCall.Returns.ProcessDefinitionSlice = []dotnetexecute.ProcessDefinition{
+						{Type: "my.app", Command: "migrator"},
+					}
+				})
+
+				it("returns an error", func() {
+					_, err := build(packit.BuildContext{
+						WorkingDir: workingDir,
+						CNBPath:    cnbDir,
+						Stack:      "some-stack",
+						BuildpackInfo: packit.BuildpackInfo{
+							Name:    "Some Buildpack",
+							Version: "some-version",
+						},
+						Plan: packit.BuildpackPlan{
+							Entries: []packit.BuildpackPlanEntry{},
+						},
+						Layers: packit.Layers{Path: layersDir},
+					})
+					Expect(err).To(MatchError(`process type "my.app" is already defined`))
+				})
+			})
+
+			context("when more than one process is marked as default", func() {
+				it.Before(func() {
+					processParser.ParseCall.Returns.ProcessDefinitionSlice = []dotnetexecute.ProcessDefinition{
+						{Type: "migrate", Command: "migrator", Default: true},
+						{Type: "worker", Command: "my.app.dll", Default: true},
+					}
+				})
+
+				it("returns an error", func() {
+					_, err := build(packit.BuildContext{
+						WorkingDir: workingDir,
+						CNBPath:    cnbDir,
+						Stack:      "some-stack",
+						BuildpackInfo: packit.BuildpackInfo{
+							Name:    "Some Buildpack",
+							Version: "some-version",
+						},
+						Plan: packit.BuildpackPlan{
+							Entries: []packit.BuildpackPlanEntry{},
+						},
+						Layers: packit.Layers{Path: layersDir},
+					})
+					Expect(err).To(MatchError("more than one additional process is marked as default"))
+				})
+			})
+
+			context("when parsing the descriptor fails", func() {
+				it.Before(func() {
+					processParser.ParseCall.Returns.Error = errors.New("failed to decode")
+				})
+
+				it("returns an error", func() {
+					_, err := build(packit.BuildContext{
+						WorkingDir: workingDir,
+						CNBPath:    cnbDir,
+						Stack:      "some-stack",
+						BuildpackInfo: packit.BuildpackInfo{
+							Name:    "Some Buildpack",
+							Version: "some-version",
+						},
+						Plan: packit.BuildpackPlan{
+							Entries: []packit.BuildpackPlanEntry{},
+						},
+						Layers: packit.Layers{Path: layersDir},
+					})
+					Expect(err).To(MatchError("failed to decode"))
+				})
+			})
+		})
+	})
+
+	context("when the publish output contains an EF Core migration bundle", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "my.app.dll"), nil, 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "efbundle"), []byte("some-apphost"), 0755)).To(Succeed())
+
+			Expect(os.MkdirAll(filepath.Join(cnbDir, "bin"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(cnbDir, "bin", "ef-migrate"), []byte("ef-migrate"), 0755)).To(Succeed())
+
+			configParser.ParseCall.Returns.RuntimeConfig = dotnetexecute.RuntimeConfig{
+				Path:           filepath.Join(workingDir, "my.app.runtimeconfig.json"),
+				AppName:        "my.app",
+				RuntimeVersion: "8.0.0",
+			}
+
+			build = dotnetexecute.Build(dotnetexecute.Configuration{
+				EFConnectionEnv: "DB_CONNECTION",
+			}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
+		})
+
+		it("adds a migrate process that runs the bundle", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:        "Some Buildpack",
+					Version:     "some-version",
+					SBOMFormats: []string{sbom.CycloneDXFormat, sbom.SPDXFormat},
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(4))
+			efMigrateLayer := result.Layers[2]
+			Expect(efMigrateLayer.Name).To(Equal("ef-migrate"))
+			Expect(efMigrateLayer.Launch).To(BeTrue())
+			Expect(filepath.Join(efMigrateLayer.Path, "bin", "ef-migrate")).To(BeARegularFile())
+
+			Expect(result.Launch.Processes).To(ContainElement(packit.Process{
+				Type:    "migrate",
+				Command: filepath.Join(layersDir, "ef-migrate", "bin", "ef-migrate"),
+				Args:    []string{"--connection-env", "DB_CONNECTION", "--", filepath.Join(workingDir, "efbundle")},
+				Direct:  true,
+			}))
+		})
+
+		context("when no connection environment variable is configured", func() {
+			it.Before(func() {
+				build = dotnetexecute.Build(dotnetexecute.Configuration{}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
+			})
+
+			it("reads the connection string from the default one", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:        "Some Buildpack",
+						Version:     "some-version",
+						SBOMFormats: []string{sbom.CycloneDXFormat, sbom.SPDXFormat},
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Launch.Processes).To(ContainElement(packit.Process{
+					Type:    "migrate",
+					Command: filepath.Join(layersDir, "ef-migrate", "bin", "ef-migrate"),
+					Args:    []string{"--connection-env", dotnetexecute.DefaultEFConnectionEnv, "--", filepath.Join(workingDir, "efbundle")},
+					Direct:  true,
+				}))
+			})
+		})
+
+		context("when a migrate process is already declared", func() {
+			it.Before(func() {
+				processParser.ParseCall.Returns.ProcessDefinitionSlice = []dotnetexecute.ProcessDefinition{
+					{Type: "migrate", Command: "efbundle", Args: []string{"--force"}},
+				}
+			})
+
+			it("keeps the declared process", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:        "Some Buildpack",
+						Version:     "some-version",
+						SBOMFormats: []string{sbom.CycloneDXFormat, sbom.SPDXFormat},
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Layers).To(HaveLen(3))
+				Expect(result.Launch.Processes).To(ContainElement(packit.Process{
+					Type:    "migrate",
+					Command: filepath.Join(workingDir, "efbundle"),
+					Args:    []string{"--force"},
+					Direct:  true,
+				}))
+			})
+		})
+
+		context("when the bundle is framework-dependent and the app is self-contained", func() {
+			it.Before(func() {
+				configParser.ParseCall.Returns.RuntimeConfig.RuntimeVersion = ""
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("is framework-dependent, but the app is self-contained")))
+			})
+		})
+	})
+
+	context("when BP_DOTNET_OTEL_AUTO_INSTRUMENTATION=true", func() {
+		var otelHome string
+
+		it.Before(func() {
+			configParser.ParseCall.Returns.RuntimeConfig = dotnetexecute.RuntimeConfig{
+				Path:       filepath.Join(workingDir, "my.app.runtimeconfig.json"),
+				AppName:    "my.app",
+				Executable: true,
+			}
+
+			otelHome = filepath.Join(workingDir, "otel")
+			Expect(writeOTelAutoInstrumentation(otelHome, "linux-x64")).To(Succeed())
+			Expect(writeOTelAutoInstrumentation(otelHome, "linux-arm64")).To(Succeed())
+
+			build = dotnetexecute.Build(dotnetexecute.Configuration{
+				OTelAutoInstrumentation:     true,
+				OTelAutoInstrumentationPath: "otel",
+			}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
+		})
+
+		it("adds a layer that loads the instrumentation at launch", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Layers:     packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(4))
+			otelLayer := result.Layers[2]
+			Expect(otelLayer.Name).To(Equal("otel-auto-instrumentation"))
+			Expect(otelLayer.Launch).To(BeTrue())
+			Expect(otelLayer.Build).To(BeFalse())
+			Expect(otelLayer.ExecD).To(Equal([]string{filepath.Join(cnbDir, "bin", "otel-bindings")}))
+
+			Expect(otelLayer.LaunchEnv).To(HaveKeyWithValue("CORECLR_ENABLE_PROFILING.override", "1"))
+			Expect(otelLayer.LaunchEnv).To(HaveKeyWithValue("CORECLR_PROFILER.override", "{918728DD-259F-4A6A-AC2B-B85E1B658318}"))
+			Expect(otelLayer.LaunchEnv).To(HaveKeyWithValue("CORECLR_PROFILER_PATH.override", MatchRegexp(`^%s/linux-(x64|arm64)/OpenTelemetry\.AutoInstrumentation\.Native\.so$`, otelHome)))
+			Expect(otelLayer.LaunchEnv).To(HaveKeyWithValue("DOTNET_STARTUP_HOOKS.append", filepath.Join(otelHome, "net", "OpenTelemetry.AutoInstrumentation.StartupHook.dll")))
+			Expect(otelLayer.LaunchEnv).To(HaveKeyWithValue("DOTNET_STARTUP_HOOKS.delim", ":"))
+			Expect(otelLayer.LaunchEnv).To(HaveKeyWithValue("DOTNET_ADDITIONAL_DEPS.append", filepath.Join(otelHome, "AdditionalDeps")))
+			Expect(otelLayer.LaunchEnv).To(HaveKeyWithValue("DOTNET_SHARED_STORE.append", filepath.Join(otelHome, "store")))
+			Expect(otelLayer.LaunchEnv).To(HaveKeyWithValue("OTEL_DOTNET_AUTO_HOME.override", otelHome))
+
+			Expect(buffer.String()).To(ContainSubstring("Configuring OpenTelemetry .NET automatic instrumentation from %s", otelHome))
+		})
+
+		context("when the instrumentation comes from an earlier buildpack", func() {
+			it.Before(func() {
+				Expect(os.Setenv("OTEL_DOTNET_AUTO_HOME", otelHome)).To(Succeed())
+
+				build = dotnetexecute.Build(dotnetexecute.Configuration{
+					OTelAutoInstrumentation: true,
+				}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("OTEL_DOTNET_AUTO_HOME")).To(Succeed())
+			})
+
+			it("uses OTEL_DOTNET_AUTO_HOME", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Layers:     packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Layers).To(HaveLen(4))
+				Expect(result.Layers[2].LaunchEnv).To(HaveKeyWithValue("OTEL_DOTNET_AUTO_HOME.override", otelHome))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when there is no instrumentation to use", func() {
+				it.Before(func() {
+					build = dotnetexecute.Build(dotnetexecute.Configuration{
+						OTelAutoInstrumentation: true,
+					}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
+				})
+
+				it("returns an error", func() {
+					_, err := build(packit.BuildContext{
+						WorkingDir: workingDir,
+						CNBPath:    cnbDir,
+						Layers:     packit.Layers{Path: layersDir},
+					})
+					Expect(err).To(MatchError("BP_DOTNET_OTEL_AUTO_INSTRUMENTATION is set, but neither BP_DOTNET_OTEL_AUTO_INSTRUMENTATION_PATH nor OTEL_DOTNET_AUTO_HOME points to the instrumentation"))
+				})
+			})
+
+			context("when the instrumentation is incomplete", func() {
+				it.Before(func() {
+					Expect(os.RemoveAll(filepath.Join(otelHome, "store"))).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := build(packit.BuildContext{
+						WorkingDir: workingDir,
+						CNBPath:    cnbDir,
+						Layers:     packit.Layers{Path: layersDir},
+					})
+					Expect(err).To(MatchError(ContainSubstring("store is missing")))
+				})
+			})
+		})
+	})
+
+	context("when the app is a single-file bundle that extracts files at startup", func() {
+		it.Before(func() {
+			configParser.ParseCall.Returns.RuntimeConfig = dotnetexecute.RuntimeConfig{
+				Path:       filepath.Join(workingDir, "my.app.runtimeconfig.json"),
+				AppName:    "my.app",
+				Executable: true,
+			}
+
+			Expect(writeSingleFileBundle(filepath.Join(workingDir, "my.app"), 6, 0,
+				bundleFile{Type: 1, Name: "my.app.dll"},
+				bundleFile{Type: 2, Name: "libe_sqlite3.so"},
+			)).To(Succeed())
+		})
+
+		it("flags the bundle", func() {
+			_, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Layers:     packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buffer.String()).To(ContainSubstring("Single-file app %s extracts 1 files at startup", filepath.Join(workingDir, "my.app")))
+			Expect(buffer.String()).To(ContainSubstring("With a read-only root filesystem, set BPL_DOTNET_READONLY_ROOTFS=true and mount a writable volume"))
+		})
+	})
+
+	context("when the app is a native AOT executable without a runtimeconfig.json", func() {
+		it.Before(func() {
+			configParser.ParseCall.Returns.Error = fmt.Errorf("no *.runtimeconfig.json found: %w", os.ErrNotExist)
+
+			Expect(writeExecutable(filepath.Join(workingDir, "my.app"), "__managedcode")).To(Succeed())
+		})
+
+		it("runs the executable and records the app kind", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Layers:     packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Launch.Processes).To(ContainElement(packit.Process{
+				Type:    "my.app",
+				Command: filepath.Join(workingDir, "my.app"),
+				Default: true,
+				Direct:  true,
+			}))
+
+			Expect(result.Layers[len(result.Layers)-1].SharedEnv).To(Equal(packit.Environment{
+				"BPI_DOTNET_APP_KIND.override": "native-aot",
+			}))
+
+			Expect(buffer.String()).To(ContainSubstring("App kind is native-aot"))
+		})
+	})
+
+	context("when the app is a single-file bundle without a runtimeconfig.json next to it", func() {
+		it.Before(func() {
+			configParser.ParseCall.Returns.Error = fmt.Errorf("no *.runtimeconfig.json found: %w", os.ErrNotExist)
+
+			Expect(writeSingleFileBundle(filepath.Join(workingDir, "my.app"), 6, 0,
+				bundleFile{Type: 1, Name: "my.app.dll"},
+				bundleFile{Type: 4, Name: "my.app.runtimeconfig.json", Content: `{
+					"runtimeOptions": {
+						"framework": {"name": "Microsoft.NETCore.App", "version": "8.0.0"}
+					}
+				}`},
+			)).To(Succeed())
+		})
+
+		it("records the app kind", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Layers:     packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[len(result.Layers)-1].SharedEnv).To(Equal(packit.Environment{
+				"BPI_DOTNET_APP_KIND.override": "single-file",
+			}))
+		})
+	})
+
+	context("when the app has image metadata", func() {
+		it.Before(func() {
+			configParser.ParseCall.Returns.RuntimeConfig = dotnetexecute.RuntimeConfig{
+				Path:    filepath.Join(workingDir, "my.app.runtimeconfig.json"),
+				AppName: "my.app",
+			}
+
+			Expect(writeAssemblyMetadata(filepath.Join(workingDir, "my.app.dll"), map[string]string{
+				"ProductName":    "My App",
+				"ProductVersion": "1.2.3+0123abcd",
+			}, map[string]string{
+				"RepositoryUrl": "https://github.com/example/my-app",
+			})).To(Succeed())
+		})
+
+		it("labels the image", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Layers:     packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Launch.Labels).To(HaveKeyWithValue("org.opencontainers.image.version", "1.2.3"))
+			Expect(result.Launch.Labels).To(HaveKeyWithValue("org.opencontainers.image.title", "My App"))
+			Expect(result.Launch.Labels).To(HaveKeyWithValue("org.opencontainers.image.source", "https://github.com/example/my-app"))
+			Expect(result.Launch.Labels).To(HaveKeyWithValue("org.opencontainers.image.revision", "0123abcd"))
+
+			Expect(buffer.String()).To(ContainSubstring("Setting image labels"))
+			Expect(buffer.String()).To(ContainSubstring("org.opencontainers.image.version: 1.2.3"))
+		})
+
+		context("when BP_DOTNET_IMAGE_LABELS is set", func() {
+			it.Before(func() {
+				build = dotnetexecute.Build(dotnetexecute.Configuration{
+					ImageLabels: `com.example.team=payments "org.opencontainers.image.title=Payments API"`,
+				}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
+			})
+
+			it("adds them over the derived labels", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Layers:     packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Launch.Labels).To(HaveKeyWithValue("com.example.team", "payments"))
+				Expect(result.Launch.Labels).To(HaveKeyWithValue("org.opencontainers.image.title", "Payments API"))
+				Expect(result.Launch.Labels).To(HaveKeyWithValue("org.opencontainers.image.version", "1.2.3"))
+			})
+		})
+
+		context("when BP_DOTNET_DISABLE_IMAGE_LABELS=true", func() {
+			it.Before(func() {
+				build = dotnetexecute.Build(dotnetexecute.Configuration{
+					DisableImageLabels: true,
+				}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
+			})
+
+			it("does not label the image", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Layers:     packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Launch.Labels).NotTo(HaveKey("org.opencontainers.image.version"))
+				Expect(buffer.String()).NotTo(ContainSubstring("Setting image labels"))
+			})
+		})
+
+		context("when BP_DOTNET_IMAGE_LABELS cannot be parsed", func() {
+			it.Before(func() {
+				build = dotnetexecute.Build(dotnetexecute.Configuration{
+					ImageLabels: "com.example.team",
+				}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Layers:     packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("failed to parse BP_DOTNET_IMAGE_LABELS")))
+			})
+		})
+	})
+
+	context("when BP_DOTNET_REPORT_PATH is set", func() {
+		var reportPath string
+
+		it.Before(func() {
+			reportPath = filepath.Join(workingDir, "reports", "dotnet-execute.json")
+
+			configParser.ParseCall.Returns.RuntimeConfig = dotnetexecute.RuntimeConfig{
+				Path:           filepath.Join(workingDir, "my.app.runtimeconfig.json"),
+				AppName:        "my.app",
+				RuntimeVersion: "8.0.0",
+			}
+			Expect(os.WriteFile(filepath.Join(workingDir, "my.app.dll"), nil, os.ModePerm)).To(Succeed())
+
+			Expect(dotnetexecute.Report{
+				AppKind: "source",
+				Files:   map[string]string{"project_file": "/path/to/my.app.csproj"},
+				Requirements: []dotnetexecute.ReportRequirement{
+					{Name: "dotnet-application", Launch: true, Reasons: []string{"my.app.csproj is built from source"}},
+					{Name: "node", Launch: true, Optional: true, Reasons: []string{"my.app.csproj runs node"}},
+					{Name: "dotnet-app-kind", Reasons: []string{"the app kind is source"}},
+				},
+			}.Write(reportPath)).To(Succeed())
+
+			build = dotnetexecute.Build(dotnetexecute.Configuration{
+				DebugEnabled: true,
+				ReportPath:   reportPath,
+			}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
+		})
+
+		it("adds its decisions to the report from detect and records it in the image", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Layers:     packit.Layers{Path: layersDir},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{
+						{Name: "dotnet-app-kind", Metadata: map[string]interface{}{"app-kind": "source"}},
+					},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			report, err := dotnetexecute.ReadReport(reportPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report).To(Equal(dotnetexecute.Report{
+				AppKind: "source",
+				Files: map[string]string{
+					"project_file":   "/path/to/my.app.csproj",
+					"runtime_config": filepath.Join(workingDir, "my.app.runtimeconfig.json"),
+					"entrypoint":     filepath.Join(workingDir, "my.app.dll"),
+					"deps_json":      filepath.Join(workingDir, "my.app.deps.json"),
+				},
+				Requirements: []dotnetexecute.ReportRequirement{
+					{Name: "dotnet-application", Launch: true, Reasons: []string{"my.app.csproj is built from source"}},
+					{Name: "node", Launch: true, Optional: true, Reasons: []string{"my.app.csproj runs node"}},
+					{Name: "dotnet-app-kind", Reasons: []string{"the app kind is source"}},
+				},
+				Processes: []dotnetexecute.ReportProcess{
+					{Type: "my.app", Command: "dotnet", Args: []string{filepath.Join(workingDir, "my.app.dll")}, Default: true},
+				},
+				LaunchEnv: map[string]map[string]string{
+					"port-chooser": {"ASPNETCORE_ENVIRONMENT.default": "Development"},
+					"app-kind":     {"BPI_DOTNET_APP_KIND.override": "source"},
+				},
+			}))
+
+			Expect(result.Launch.Labels).To(HaveKey(dotnetexecute.ReportLabel))
+
+			var labelReport dotnetexecute.Report
+			Expect(json.Unmarshal([]byte(result.Launch.Labels[dotnetexecute.ReportLabel]), &labelReport)).To(Succeed())
+			report.LaunchEnv = nil
+			Expect(labelReport).To(Equal(report))
+
+			Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("Writing report to %s", reportPath)))
+		})
+
+		context("when there is no report from detect", func() {
+			it.Before(func() {
+				Expect(os.RemoveAll(reportPath)).To(Succeed())
+			})
+
+			it("reports the app kind from the build plan", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Layers:     packit.Layers{Path: layersDir},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{
+							{Name: "dotnet-app-kind", Metadata: map[string]interface{}{"app-kind": "framework-dependent-deployment"}},
+						},
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				report, err := dotnetexecute.ReadReport(reportPath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(report.Requirements).To(Equal([]dotnetexecute.ReportRequirement{
+					{Name: "dotnet-app-kind", Reasons: []string{"the app kind is framework-dependent-deployment"}},
+				}))
+			})
+		})
+
+		context("when BP_DOTNET_REPORT_PATH is relative", func() {
+			it.Before(func() {
+				build = dotnetexecute.Build(dotnetexecute.Configuration{
+					ReportPath: filepath.Join("reports", "dotnet-execute.json"),
+				}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
+			})
+
+			it("reads and writes the report in the app root", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Layers:     packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				report, err := dotnetexecute.ReadReport(reportPath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(report.Files).To(HaveKeyWithValue("project_file", "/path/to/my.app.csproj"))
+				Expect(report.Processes).To(HaveLen(1))
+
+				Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("Writing report to %s", reportPath)))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the report from detect can not be decoded", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(reportPath, []byte("%%%"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := build(packit.BuildContext{
+						WorkingDir: workingDir,
+						CNBPath:    cnbDir,
+						Layers:     packit.Layers{Path: layersDir},
+					})
+					Expect(err).To(MatchError(ContainSubstring("failed to decode report")))
+				})
+			})
+
+			context("when the report from detect can not be read", func() {
+				it.Before(func() {
+					Expect(os.RemoveAll(filepath.Join(workingDir, "reports"))).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(workingDir, "reports"), nil, 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := build(packit.BuildContext{
+						WorkingDir: workingDir,
+						CNBPath:    cnbDir,
+						Layers:     packit.Layers{Path: layersDir},
+					})
+					Expect(err).To(MatchError(ContainSubstring("failed to read report")))
+				})
+			})
+		})
+	})
+
+	context("when there are startup hooks", func() {
+		var (
+			bindingRoot    string
+			otherBuildpack string
+		)
+
+		it.Before(func() {
+			configParser.ParseCall.Returns.RuntimeConfig = dotnetexecute.RuntimeConfig{
+				Path:           filepath.Join(workingDir, "my.app.runtimeconfig.json"),
+				AppName:        "my.app",
+				RuntimeVersion: "8.0.4",
+				Executable:     true,
+			}
+
+			Expect(writeAssembly(filepath.Join(workingDir, "hooks", "Secrets.dll"), ".NETCoreApp,Version=v8.0")).To(Succeed())
+
+			var err error
+			otherBuildpack, err = os.MkdirTemp(filepath.Dir(layersDir), "other-buildpack")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(writeAssembly(filepath.Join(otherBuildpack, "agent", "startup-hooks", "Agent.dll"), ".NETStandard,Version=v2.0")).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(otherBuildpack, "agent.toml"), []byte("[types]\n  launch = true\n"), 0600)).To(Succeed())
+
+			bindingRoot, err = os.MkdirTemp("", "bindings")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.Setenv("SERVICE_BINDING_ROOT", bindingRoot)).To(Succeed())
+
+			Expect(os.MkdirAll(filepath.Join(bindingRoot, "tenant"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(bindingRoot, "tenant", "type"), []byte("dotnet-startup-hooks"), 0600)).To(Succeed())
+			Expect(writeAssembly(filepath.Join(bindingRoot, "tenant", "Tenant.dll"), "")).To(Succeed())
+
+			build = dotnetexecute.Build(dotnetexecute.Configuration{
+				StartupHooks: "hooks/Secrets.dll",
+			}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("SERVICE_BINDING_ROOT")).To(Succeed())
+			Expect(os.RemoveAll(bindingRoot)).To(Succeed())
+			Expect(os.RemoveAll(otherBuildpack)).To(Succeed())
+		})
+
+		it("adds them to DOTNET_STARTUP_HOOKS on a launch layer", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Layers:     packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
 
-			Expect(sbomGenerator.GenerateCall.Receives.Path).To(Equal(workingDir))
+			Expect(result.Layers).To(HaveLen(4))
+			hooksLayer := result.Layers[2]
+			Expect(hooksLayer.Name).To(Equal("startup-hooks"))
+			Expect(hooksLayer.Launch).To(BeTrue())
+
+			tenantHook := filepath.Join(layersDir, "startup-hooks", "tenant", "Tenant.dll")
+			Expect(tenantHook).To(BeARegularFile())
+
+			Expect(hooksLayer.LaunchEnv).To(Equal(packit.Environment{
+				"DOTNET_STARTUP_HOOKS.append": strings.Join([]string{
+					filepath.Join(workingDir, "hooks", "Secrets.dll"),
+					filepath.Join(otherBuildpack, "agent", "startup-hooks", "Agent.dll"),
+					tenantHook,
+				}, ":"),
+				"DOTNET_STARTUP_HOOKS.delim": ":",
+			}))
+
+			Expect(buffer.String()).To(ContainSubstring("Adding startup hooks"))
+			Expect(buffer.String()).To(ContainSubstring("Secrets.dll (.NETCoreApp,Version=v8.0)"))
+			Expect(buffer.String()).To(ContainSubstring("Agent.dll (.NETStandard,Version=v2.0)"))
+		})
+
+		context("failure cases", func() {
+			context("when a hook targets a newer runtime than the app", func() {
+				it.Before(func() {
+					Expect(writeAssembly(filepath.Join(workingDir, "hooks", "Secrets.dll"), ".NETCoreApp,Version=v9.0")).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := build(packit.BuildContext{
+						WorkingDir: workingDir,
+						CNBPath:    cnbDir,
+						Layers:     packit.Layers{Path: layersDir},
+					})
+					Expect(err).To(MatchError(ContainSubstring("which is newer than the app's runtime 8.0.4")))
+				})
+			})
+
+			context("when a hook from the setting does not exist", func() {
+				it.Before(func() {
+					build = dotnetexecute.Build(dotnetexecute.Configuration{
+						StartupHooks: "hooks/Missing.dll",
+					}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
+				})
+
+				it("returns an error", func() {
+					_, err := build(packit.BuildContext{
+						WorkingDir: workingDir,
+						CNBPath:    cnbDir,
+						Layers:     packit.Layers{Path: layersDir},
+					})
+					Expect(err).To(MatchError(ContainSubstring("Missing.dll not found")))
+				})
+			})
 		})
 	})
 
-	context("the app is a framework dependent deployment", func() {
+	context("when the app needs native libraries", func() {
 		it.Before(func() {
 			configParser.ParseCall.Returns.RuntimeConfig = dotnetexecute.RuntimeConfig{
-				Path:       filepath.Join(workingDir, "my.app.runtimeconfig.json"),
-				AppName:    "my.app",
-				Executable: false,
+				Path:       filepath.Join(workingDir, "myapp.runtimeconfig.json"),
+				AppName:    "myapp",
+				Executable: true,
 			}
-			Expect(os.WriteFile(filepath.Join(workingDir, "my.app.dll"), nil, os.ModePerm)).To(Succeed())
-		})
 
-		it.After(func() {
-			Expect(os.RemoveAll(filepath.Join(workingDir, "my.app.dll"))).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "myapp.deps.json"), []byte(`{
+				"libraries": {
+					"System.Drawing.Common/8.0.0": { "type": "package" },
+					"myapp/1.0.0": { "type": "project" }
+				}
+			}`), 0600)).To(Succeed())
 		})
 
-		it("returns a result that builds correctly", func() {
-			result, err := build(packit.BuildContext{
+		it("lists the libraries that the app needs", func() {
+			_, err := build(packit.BuildContext{
 				WorkingDir: workingDir,
 				CNBPath:    cnbDir,
-				Stack:      "some-stack",
-				BuildpackInfo: packit.BuildpackInfo{
-					Name:        "Some Buildpack",
-					Version:     "some-version",
-					SBOMFormats: []string{sbom.CycloneDXFormat, sbom.SPDXFormat},
-				},
 				Plan: packit.BuildpackPlan{
-					Entries: []packit.BuildpackPlanEntry{},
+					Entries: []packit.BuildpackPlanEntry{
+						{Name: dotnetexecute.AppKindPlanEntry, Metadata: map[string]interface{}{"app-kind": "framework-dependent-executable"}},
+					},
 				},
 				Layers: packit.Layers{Path: layersDir},
 			})
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(result.Layers).To(HaveLen(1))
-			portLayer := result.Layers[0]
+			Expect(buffer.String()).To(ContainSubstring("The app needs system libraries that are not part of every run image"))
+			Expect(buffer.String()).To(ContainSubstring("libgdiplus, needed by package System.Drawing.Common"))
+			Expect(buffer.String()).To(ContainSubstring("libfontconfig, needed by package System.Drawing.Common"))
+			Expect(buffer.String()).To(ContainSubstring("The app may fail at runtime unless a buildpack provides them or the run image includes them"))
+		})
 
-			Expect(portLayer.Name).To(Equal("port-chooser"))
-			Expect(portLayer.Path).To(Equal(filepath.Join(layersDir, "port-chooser")))
-			Expect(portLayer.ExecD).To(Equal([]string{filepath.Join(cnbDir, "bin", "port-chooser")}))
+		context("when the deps.json can not be decoded", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "myapp.deps.json"), []byte("%%%"), 0600)).To(Succeed())
+			})
 
-			Expect(result.Launch.Processes).To(Equal([]packit.Process{
-				{
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Layers:     packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("failed to decode")))
+			})
+		})
+	})
 
-					Type:    "my.app",
-					Command: "dotnet",
-					Args:    []string{filepath.Join(workingDir, "my.app.dll")},
-					Default: true,
-					Direct:  true,
+	context("when native libraries in the app need shared libraries that are missing", func() {
+		it.Before(func() {
+			configParser.ParseCall.Returns.RuntimeConfig = dotnetexecute.RuntimeConfig{
+				Path:       filepath.Join(workingDir, "myapp.runtimeconfig.json"),
+				AppName:    "myapp",
+				Executable: true,
+			}
+
+			Expect(writeSharedLibrary(filepath.Join(workingDir, "libNative.so"), "libmissing.so.1")).To(Succeed())
+		})
+
+		it("warns about the missing libraries", func() {
+			_, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Layers:     packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buffer.String()).To(ContainSubstring("Warning: native libraries in the app need shared libraries that were not found"))
+			Expect(buffer.String()).To(ContainSubstring("libmissing.so.1, needed by libNative.so"))
+			Expect(buffer.String()).To(ContainSubstring("The app may fail at startup unless the run image includes them"))
+		})
+
+		context("when BP_DOTNET_STRICT_NATIVE_LIBRARIES=true", func() {
+			it.Before(func() {
+				build = dotnetexecute.Build(dotnetexecute.Configuration{StrictNativeLibraries: true}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Layers:     packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError("native libraries in the app need shared libraries that were not found: libmissing.so.1"))
+			})
+		})
+	})
+
+	context("when the publish output does not match its deps.json", func() {
+		it.Before(func() {
+			configParser.ParseCall.Returns.RuntimeConfig = dotnetexecute.RuntimeConfig{
+				Path:           filepath.Join(workingDir, "myapp.runtimeconfig.json"),
+				AppName:        "myapp",
+				RuntimeVersion: "8.0.0",
+			}
+
+			Expect(os.WriteFile(filepath.Join(workingDir, "myapp.dll"), nil, 0600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "myapp.deps.json"), []byte(`{
+				"runtimeTarget": {"name": ".NETCoreApp,Version=v8.0"},
+				"targets": {
+					".NETCoreApp,Version=v8.0": {
+						"myapp/1.0.0": {"runtime": {"myapp.dll": {}}},
+						"Newtonsoft.Json/13.0.3": {"runtime": {"lib/net6.0/Newtonsoft.Json.dll": {}}}
+					}
 				},
-			}))
+				"libraries": {
+					"myapp/1.0.0": {"type": "project"},
+					"Newtonsoft.Json/13.0.3": {"type": "package"}
+				}
+			}`), 0600)).To(Succeed())
+		})
+
+		it("warns about each problem and records them in the report", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Layers:     packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buffer.String()).To(ContainSubstring("Warning: the publish output in %s is incomplete or inconsistent", workingDir))
+			Expect(buffer.String()).To(ContainSubstring("myapp.deps.json lists Newtonsoft.Json.dll from Newtonsoft.Json/13.0.3, but it is missing"))
+			Expect(buffer.String()).To(ContainSubstring("The app may fail at startup with a FileNotFoundException"))
+
+			Expect(result.Launch.Labels[dotnetexecute.ReportLabel]).To(ContainSubstring(`"publish_problems"`))
 		})
 	})
 
@@ -251,7 +1460,7 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 
 			build = dotnetexecute.Build(dotnetexecute.Configuration{
 				LiveReloadEnabled: true,
-			}, configParser, sbomGenerator, logger, chronos.DefaultClock)
+			}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
 		})
 
 		it.After(func() {
@@ -282,6 +1491,10 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 					Args: []string{
 						"--restart",
 						"--watch", workingDir,
+						"--filter", "*.dll",
+						"--filter", "*.json",
+						"--filter", "*.config",
+						"--ignore", "wwwroot/**",
 						"--shell", "none",
 						"--",
 						"dotnet",
@@ -299,7 +1512,49 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			}))
 		})
 
-		it("marks all files in the workspace as group read-writable", func() {
+		context("when BP_LIVE_RELOAD_WATCH and BP_LIVE_RELOAD_IGNORE are set", func() {
+			it.Before(func() {
+				build = dotnetexecute.Build(dotnetexecute.Configuration{
+					LiveReloadEnabled: true,
+					LiveReloadWatch:   "bin, *.dll,*.so,src/**/*.cs",
+					LiveReloadIgnore:  "logs/**,*.log",
+				}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
+			})
+
+			it("passes the patterns to watchexec", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:        "Some Buildpack",
+						Version:     "some-version",
+						SBOMFormats: []string{sbom.CycloneDXFormat, sbom.SPDXFormat},
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Launch.Processes[0].Args).To(Equal([]string{
+					"--restart",
+					"--watch", filepath.Join(workingDir, "bin"),
+					"--filter", "*.dll",
+					"--filter", "*.so",
+					"--filter", "src/**/*.cs",
+					"--ignore", "logs/**",
+					"--ignore", "*.log",
+					"--shell", "none",
+					"--",
+					"dotnet",
+					filepath.Join(workingDir, "my.app.dll"),
+				}))
+			})
+		})
+
+		it("marks the watched files in the workspace as group read-writable", func() {
 			_, err := build(packit.BuildContext{
 				WorkingDir: workingDir,
 				CNBPath:    cnbDir,
@@ -331,6 +1586,238 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 				fs.FileMode(0660),
 			))
 		})
+
+		context("when there are files that are not watched", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "my.app"), nil, 0700)).To(Succeed())
+				Expect(os.MkdirAll(filepath.Join(workingDir, "src", "app"), 0700)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, "src", "app", "Program.cs"), nil, 0600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, "src", "appsettings.Production.json"), nil, 0600)).To(Succeed())
+				Expect(os.MkdirAll(filepath.Join(workingDir, "logs"), 0700)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, "logs", "app.log"), nil, 0600)).To(Succeed())
+
+				build = dotnetexecute.Build(dotnetexecute.Configuration{
+					LiveReloadEnabled: true,
+					LiveReloadWatch:   "*.dll,src/**/*.cs",
+				}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
+			})
+
+			it("passes glob patterns to watchexec as filters and leaves the other files alone", func() {
+				result, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Layers:     packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Launch.Processes[0].Args).To(Equal([]string{
+					"--restart",
+					"--watch", workingDir,
+					"--filter", "*.dll",
+					"--filter", "src/**/*.cs",
+					"--ignore", "wwwroot/**",
+					"--shell", "none",
+					"--",
+					"dotnet",
+					filepath.Join(workingDir, "my.app.dll"),
+				}))
+
+				modes := map[string]fs.FileMode{}
+				for _, path := range []string{
+					filepath.Join(workingDir, "my.app.dll"),
+					filepath.Join(workingDir, "my.app"),
+					filepath.Join(workingDir, "src"),
+					filepath.Join(workingDir, "src", "app"),
+					filepath.Join(workingDir, "src", "app", "Program.cs"),
+					filepath.Join(workingDir, "src", "appsettings.Production.json"),
+					filepath.Join(workingDir, "logs"),
+					filepath.Join(workingDir, "logs", "app.log"),
+				} {
+					info, err := os.Stat(path)
+					Expect(err).NotTo(HaveOccurred())
+					modes[path] = info.Mode().Perm()
+				}
+
+				Expect(modes).To(Equal(map[string]fs.FileMode{
+					filepath.Join(workingDir, "my.app.dll"):                         0660,
+					filepath.Join(workingDir, "my.app"):                             0700,
+					filepath.Join(workingDir, "src"):                                0770,
+					filepath.Join(workingDir, "src", "app"):                         0770,
+					filepath.Join(workingDir, "src", "app", "Program.cs"):           0660,
+					filepath.Join(workingDir, "src", "appsettings.Production.json"): 0600,
+					filepath.Join(workingDir, "logs"):                               0700,
+					filepath.Join(workingDir, "logs", "app.log"):                    0600,
+				}))
+
+				Expect(buffer.String()).To(ContainSubstring("Updated 2 files and 2 directories"))
+			})
+		})
+	})
+
+	context("when BP_LIVE_RELOAD_ENABLED=true and only some paths are watched", func() {
+		var outsideDir string
+
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "my.app.dll"), nil, 0600)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(workingDir, "bin"), 0700)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "bin", "app.dll"), nil, 0600)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(workingDir, "data"), 0700)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "data", "seed.json"), nil, 0600)).To(Succeed())
+
+			var err error
+			outsideDir, err = os.MkdirTemp("", "outside")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(filepath.Join(outsideDir, "secret"), nil, 0600)).To(Succeed())
+			Expect(os.Symlink(filepath.Join(outsideDir, "secret"), filepath.Join(workingDir, "bin", "secret"))).To(Succeed())
+			Expect(os.Symlink(outsideDir, filepath.Join(workingDir, "outside"))).To(Succeed())
+
+			configParser.ParseCall.Returns.RuntimeConfig = dotnetexecute.RuntimeConfig{
+				Path:       filepath.Join(workingDir, "my.app.runtimeconfig.json"),
+				AppName:    "my.app",
+				Executable: false,
+			}
+
+			build = dotnetexecute.Build(dotnetexecute.Configuration{
+				LiveReloadEnabled:       true,
+				LiveReloadWatch:         "bin,outside,*.dll",
+				LiveReloadWritablePaths: "data/seed.json",
+			}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(outsideDir)).To(Succeed())
+		})
+
+		it("only widens access to the watched and writable paths", func() {
+			_, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:        "Some Buildpack",
+					Version:     "some-version",
+					SBOMFormats: []string{sbom.CycloneDXFormat, sbom.SPDXFormat},
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			modes := map[string]fs.FileMode{}
+			for _, path := range []string{
+				filepath.Join(workingDir, "my.app.dll"),
+				filepath.Join(workingDir, "bin"),
+				filepath.Join(workingDir, "bin", "app.dll"),
+				filepath.Join(workingDir, "data"),
+				filepath.Join(workingDir, "data", "seed.json"),
+				filepath.Join(outsideDir, "secret"),
+			} {
+				info, err := os.Stat(path)
+				Expect(err).NotTo(HaveOccurred())
+				modes[path] = info.Mode().Perm()
+			}
+
+			Expect(modes).To(Equal(map[string]fs.FileMode{
+				filepath.Join(workingDir, "my.app.dll"):        0600,
+				filepath.Join(workingDir, "bin"):               0770,
+				filepath.Join(workingDir, "bin", "app.dll"):    0660,
+				filepath.Join(workingDir, "data"):              0700,
+				filepath.Join(workingDir, "data", "seed.json"): 0660,
+				filepath.Join(outsideDir, "secret"):            0600,
+			}))
+
+			Expect(buffer.String()).To(ContainSubstring("Updated 2 files and 1 directories"))
+			Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("Skipped %s: resolves outside of the app root", filepath.Join(workingDir, "outside"))))
+		})
+	})
+
+	context("when BP_LIVE_RELOAD_MODE=source", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(workingDir, "src", "app"), 0700)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "src", "app", "app.csproj"), nil, 0600)).To(Succeed())
+
+			projectParser.FindProjectFileCall.Returns.ProjectSelection = dotnetexecute.ProjectSelection{Path: filepath.Join(workingDir, "src", "app", "app.csproj")}
+			projectParser.ImageMetadataCall.Returns.ImageMetadata = dotnetexecute.ImageMetadata{Title: "app", Licenses: "MIT"}
+
+			build = dotnetexecute.Build(dotnetexecute.Configuration{
+				LiveReloadEnabled: true,
+				LiveReloadMode:    "source",
+				ProjectPath:       "src/app",
+			}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
+		})
+
+		it("runs the project with dotnet watch", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:        "Some Buildpack",
+					Version:     "some-version",
+					SBOMFormats: []string{sbom.CycloneDXFormat, sbom.SPDXFormat},
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(configParser.ParseCall.CallCount).To(Equal(0))
+			Expect(projectParser.FindProjectFileCall.Receives.Root).To(Equal(workingDir))
+			Expect(projectParser.FindProjectFileCall.Receives.Dir).To(Equal(filepath.Join(workingDir, "src", "app")))
+
+			Expect(result.Launch.Processes).To(Equal([]packit.Process{
+				{
+					Type:    "reload-app",
+					Command: "dotnet",
+					Args:    []string{"watch", "run", "--project", filepath.Join(workingDir, "src", "app", "app.csproj")},
+					Default: true,
+					Direct:  true,
+				},
+			}))
+
+			Expect(result.Layers).To(HaveLen(3))
+			Expect(result.Layers[0].LaunchEnv).To(Equal(packit.Environment{
+				"DOTNET_USE_POLLING_FILE_WATCHER.default":      "true",
+				"DOTNET_WATCH_RESTART_ON_RUDE_EDIT.default":    "true",
+				"DOTNET_WATCH_SUPPRESS_LAUNCH_BROWSER.default": "true",
+			}))
+
+			Expect(projectParser.ImageMetadataCall.Receives.Root).To(Equal(workingDir))
+			Expect(projectParser.ImageMetadataCall.Receives.Path).To(Equal(filepath.Join(workingDir, "src", "app", "app.csproj")))
+			Expect(result.Launch.Labels).To(HaveKeyWithValue("org.opencontainers.image.title", "app"))
+			Expect(result.Launch.Labels).To(HaveKeyWithValue("org.opencontainers.image.licenses", "MIT"))
+
+			info, err := os.Stat(filepath.Join(workingDir, "src", "app", "app.csproj"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(fs.FileMode(0660)))
+		})
+
+		context("when there is no project file", func() {
+			it.Before(func() {
+				projectParser.FindProjectFileCall.Returns.ProjectSelection = dotnetexecute.ProjectSelection{}
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("no project file found in")))
+			})
+		})
 	})
 
 	context("when BP_DEBUG_ENABLED=true", func() {
@@ -344,7 +1831,7 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 
 			build = dotnetexecute.Build(dotnetexecute.Configuration{
 				DebugEnabled: true,
-			}, configParser, sbomGenerator, logger, chronos.DefaultClock)
+			}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
 		})
 
 		it.After(func() {
@@ -368,7 +1855,7 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			})
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(result.Layers).To(HaveLen(1))
+			Expect(result.Layers).To(HaveLen(3))
 			portLayer := result.Layers[0]
 
 			Expect(portLayer.Name).To(Equal("port-chooser"))
@@ -477,6 +1964,31 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 
 		})
 
+		context("when BP_DOTNET_RUN_ARGS cannot be parsed", func() {
+			it.Before(func() {
+				build = dotnetexecute.Build(dotnetexecute.Configuration{
+					RunArgs: `--name 'oops`,
+				}, configParser, projectParser, processParser, sbomGenerator, logger, chronos.DefaultClock)
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).To(MatchError(ContainSubstring("unterminated ' quote")))
+			})
+		})
+
 		context("when generating the SBOM returns an error", func() {
 			it.Before(func() {
 				sbomGenerator.GenerateCall.Returns.Error = errors.New("failed to generate SBOM")
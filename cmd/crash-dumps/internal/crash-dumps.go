@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/paketo-buildpacks/dotnet-execute/cmd/internal/launch"
+)
+
+const (
+	// EnableMiniDump turns on dump collection by the .NET runtime when the
+	// app crashes.
+	EnableMiniDump = "DOTNET_DbgEnableMiniDump"
+
+	// MiniDumpType selects how much of the process memory createdump writes.
+	MiniDumpType = "DOTNET_DbgMiniDumpType"
+
+	// MiniDumpName is the path of the dump file. createdump replaces %e with
+	// the executable name, %p with the process ID and %t with the time of the
+	// crash.
+	MiniDumpName = "DOTNET_DbgMiniDumpName"
+
+	// DefaultDirectory is where dumps are written when
+	// BPL_DOTNET_CRASH_DUMPS_PATH is not set.
+	DefaultDirectory = "/tmp/dumps"
+)
+
+var dumpTypes = map[string]string{
+	"mini":   "1",
+	"heap":   "2",
+	"triage": "3",
+	"full":   "4",
+}
+
+// ConfigureCrashDumps returns the environment variables that make the .NET
+// runtime write a dump with createdump when the app crashes.
+// BPL_DOTNET_CRASH_DUMPS=true turns the feature on, BPL_DOTNET_CRASH_DUMPS_PATH
+// chooses the directory and BPL_DOTNET_CRASH_DUMPS_TYPE chooses one of mini,
+// heap, triage or full. If DOTNET_DbgEnableMiniDump is already set, no further
+// action is taken.
+//
+// An error is returned when the directory is not writable or createdump can
+// not be found in appDir or the shared runtime under DOTNET_ROOT.
+func ConfigureCrashDumps(appDir string) (map[string]string, error) {
+	if _, ok := os.LookupEnv(EnableMiniDump); ok {
+		return map[string]string{}, nil
+	}
+
+	enabled := false
+	if value, ok := os.LookupEnv("BPL_DOTNET_CRASH_DUMPS"); ok && value != "" {
+		var err error
+		enabled, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BPL_DOTNET_CRASH_DUMPS %q: %w", value, err)
+		}
+	}
+
+	if !enabled {
+		return map[string]string{}, nil
+	}
+
+	dumpType := "heap"
+	if value := os.Getenv("BPL_DOTNET_CRASH_DUMPS_TYPE"); value != "" {
+		dumpType = strings.ToLower(value)
+	}
+
+	typeValue, ok := dumpTypes[dumpType]
+	if !ok {
+		return nil, fmt.Errorf("invalid BPL_DOTNET_CRASH_DUMPS_TYPE %q: expected one of mini, heap, triage or full", dumpType)
+	}
+
+	dir := DefaultDirectory
+	if value := os.Getenv("BPL_DOTNET_CRASH_DUMPS_PATH"); value != "" {
+		dir = value
+	}
+
+	err := launch.CheckWritable(dir)
+	if err != nil {
+		return nil, fmt.Errorf("crash dump directory %w", err)
+	}
+
+	createdump, err := findCreatedump(appDir, os.Getenv("DOTNET_ROOT"))
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("Writing %s crash dumps to %s with %s\n", dumpType, dir, createdump)
+
+	return map[string]string{
+		EnableMiniDump: "1",
+		MiniDumpType:   typeValue,
+		MiniDumpName:   filepath.Join(dir, "coredump.%e.%p.%t"),
+	}, nil
+}
+
+// findCreatedump looks for createdump next to a self-contained app, then in
+// the shared runtime.
+func findCreatedump(appDir, dotnetRoot string) (string, error) {
+	candidates := []string{filepath.Join(appDir, "createdump")}
+
+	if dotnetRoot != "" {
+		matches, err := filepath.Glob(filepath.Join(dotnetRoot, "shared", "Microsoft.NETCore.App", "*", "createdump"))
+		if err != nil {
+			return "", err
+		}
+		candidates = append(candidates, matches...)
+	}
+
+	for _, candidate := range candidates {
+		info, err := os.Stat(candidate)
+		if err == nil && info.Mode().IsRegular() && info.Mode()&0111 != 0 {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("createdump was not found in %s or the shared runtime", appDir)
+}
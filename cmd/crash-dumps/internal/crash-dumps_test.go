@@ -0,0 +1,182 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/dotnet-execute/cmd/crash-dumps/internal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testConfigureCrashDumps(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		appDir     string
+		dotnetRoot string
+		dumpDir    string
+	)
+
+	it.Before(func() {
+		var err error
+		appDir, err = os.MkdirTemp("", "app")
+		Expect(err).NotTo(HaveOccurred())
+
+		dotnetRoot, err = os.MkdirTemp("", "dotnet-root")
+		Expect(err).NotTo(HaveOccurred())
+
+		dumpDir, err = os.MkdirTemp("", "dumps")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(dotnetRoot, "shared", "Microsoft.NETCore.App", "8.0.4"), os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dotnetRoot, "shared", "Microsoft.NETCore.App", "8.0.4", "createdump"), nil, 0755)).To(Succeed())
+
+		Expect(os.Setenv("DOTNET_ROOT", dotnetRoot)).To(Succeed())
+		Expect(os.Setenv("BPL_DOTNET_CRASH_DUMPS", "true")).To(Succeed())
+		Expect(os.Setenv("BPL_DOTNET_CRASH_DUMPS_PATH", filepath.Join(dumpDir, "app"))).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.Unsetenv("DOTNET_ROOT")).To(Succeed())
+		Expect(os.Unsetenv("DOTNET_DbgEnableMiniDump")).To(Succeed())
+		Expect(os.Unsetenv("BPL_DOTNET_CRASH_DUMPS")).To(Succeed())
+		Expect(os.Unsetenv("BPL_DOTNET_CRASH_DUMPS_PATH")).To(Succeed())
+		Expect(os.Unsetenv("BPL_DOTNET_CRASH_DUMPS_TYPE")).To(Succeed())
+
+		Expect(os.RemoveAll(appDir)).To(Succeed())
+		Expect(os.RemoveAll(dotnetRoot)).To(Succeed())
+		Expect(os.RemoveAll(dumpDir)).To(Succeed())
+	})
+
+	it("enables heap dumps in the configured directory", func() {
+		envVars, err := internal.ConfigureCrashDumps(appDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(envVars).To(Equal(map[string]string{
+			"DOTNET_DbgEnableMiniDump": "1",
+			"DOTNET_DbgMiniDumpType":   "2",
+			"DOTNET_DbgMiniDumpName":   filepath.Join(dumpDir, "app", "coredump.%e.%p.%t"),
+		}))
+		Expect(filepath.Join(dumpDir, "app")).To(BeADirectory())
+	})
+
+	context("when BPL_DOTNET_CRASH_DUMPS is not set", func() {
+		it.Before(func() {
+			Expect(os.Unsetenv("BPL_DOTNET_CRASH_DUMPS")).To(Succeed())
+		})
+
+		it("does nothing", func() {
+			envVars, err := internal.ConfigureCrashDumps(appDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envVars).To(BeEmpty())
+		})
+	})
+
+	context("when BPL_DOTNET_CRASH_DUMPS is false", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BPL_DOTNET_CRASH_DUMPS", "false")).To(Succeed())
+		})
+
+		it("does nothing", func() {
+			envVars, err := internal.ConfigureCrashDumps(appDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envVars).To(BeEmpty())
+		})
+	})
+
+	context("when DOTNET_DbgEnableMiniDump is already set", func() {
+		it.Before(func() {
+			Expect(os.Setenv("DOTNET_DbgEnableMiniDump", "0")).To(Succeed())
+		})
+
+		it("does nothing", func() {
+			envVars, err := internal.ConfigureCrashDumps(appDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envVars).To(BeEmpty())
+		})
+	})
+
+	context("when BPL_DOTNET_CRASH_DUMPS_TYPE is set", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BPL_DOTNET_CRASH_DUMPS_TYPE", "Full")).To(Succeed())
+		})
+
+		it("uses that dump type", func() {
+			envVars, err := internal.ConfigureCrashDumps(appDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envVars).To(HaveKeyWithValue("DOTNET_DbgMiniDumpType", "4"))
+		})
+	})
+
+	context("when the app is self-contained", func() {
+		it.Before(func() {
+			Expect(os.Unsetenv("DOTNET_ROOT")).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(appDir, "createdump"), nil, 0755)).To(Succeed())
+		})
+
+		it("uses the createdump shipped with the app", func() {
+			envVars, err := internal.ConfigureCrashDumps(appDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envVars).To(HaveKeyWithValue("DOTNET_DbgEnableMiniDump", "1"))
+		})
+	})
+
+	context("failure cases", func() {
+		context("when BPL_DOTNET_CRASH_DUMPS is not a boolean", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BPL_DOTNET_CRASH_DUMPS", "sometimes")).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := internal.ConfigureCrashDumps(appDir)
+				Expect(err).To(MatchError(ContainSubstring(`invalid BPL_DOTNET_CRASH_DUMPS "sometimes"`)))
+			})
+		})
+
+		context("when BPL_DOTNET_CRASH_DUMPS_TYPE is unknown", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BPL_DOTNET_CRASH_DUMPS_TYPE", "huge")).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := internal.ConfigureCrashDumps(appDir)
+				Expect(err).To(MatchError(`invalid BPL_DOTNET_CRASH_DUMPS_TYPE "huge": expected one of mini, heap, triage or full`))
+			})
+		})
+
+		context("when the dump directory is not writable", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(dumpDir, "app"), nil, 0600)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := internal.ConfigureCrashDumps(appDir)
+				Expect(err).To(MatchError(ContainSubstring("can not be created")))
+			})
+		})
+
+		context("when createdump can not be found", func() {
+			it.Before(func() {
+				Expect(os.RemoveAll(filepath.Join(dotnetRoot, "shared"))).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := internal.ConfigureCrashDumps(appDir)
+				Expect(err).To(MatchError(ContainSubstring("createdump was not found")))
+			})
+		})
+
+		context("when createdump is not executable", func() {
+			it.Before(func() {
+				Expect(os.Chmod(filepath.Join(dotnetRoot, "shared", "Microsoft.NETCore.App", "8.0.4", "createdump"), 0644)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := internal.ConfigureCrashDumps(appDir)
+				Expect(err).To(MatchError(ContainSubstring("createdump was not found")))
+			})
+		})
+	})
+}
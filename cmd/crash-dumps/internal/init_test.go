@@ -0,0 +1,14 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitCrashDumps(t *testing.T) {
+	suite := spec.New("crash-dumps", spec.Report(report.Terminal{}), spec.Sequential())
+	suite("ConfigureCrashDumps", testConfigureCrashDumps)
+	suite.Run(t)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/paketo-buildpacks/dotnet-execute/cmd/crash-dumps/internal"
+)
+
+// main will configure crash dump collection when BPL_DOTNET_CRASH_DUMPS is
+// set, and write the resulting environment variables to FD 3. A problem with
+// the configuration is reported, but does not stop the app from starting.
+// See https://github.com/buildpacks/rfcs/blob/main/text/0093-remove-shell-processes.md.
+func main() {
+	execdWriter := os.NewFile(3, "/dev/fd/3")
+
+	appDir, err := os.Getwd()
+	if err != nil {
+		return
+	}
+
+	envVars, err := internal.ConfigureCrashDumps(appDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Crash dumps are disabled: %s\n", err)
+		return
+	}
+
+	for k, v := range envVars {
+		if _, err := fmt.Fprintf(execdWriter, "%s=%s\n", k, strconv.Quote(v)); err != nil {
+			return
+		}
+	}
+}
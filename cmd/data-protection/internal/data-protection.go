@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/paketo-buildpacks/dotnet-execute/cmd/internal/launch"
+	"github.com/paketo-buildpacks/packit/v2/servicebindings"
+)
+
+const (
+	// BindingType is the type of the service binding that holds the data
+	// protection key location.
+	BindingType = "dataprotection"
+
+	// PathKey is the binding entry that names the key directory. Bindings are
+	// mounted read-only, so the keys can not be kept in the binding itself.
+	PathKey = "path"
+)
+
+// ConfigureDataProtection returns the environment variables that point ASP.NET
+// Core data protection at a persisted key directory. The location is taken
+// from the path entry of a "dataprotection" service binding when there is
+// one, and from BPL_DOTNET_DATAPROTECTION_PATH otherwise. LOCALAPPDATA is set
+// to the location, so that the default key directory,
+// $LOCALAPPDATA/ASP.NET/DataProtection-Keys, is persisted without changing
+// HOME for the rest of the app, and DataProtection__KeyPath is set to that
+// directory for apps that pass it to PersistKeysToFileSystem. If either
+// variable is already set, no further action is taken.
+//
+// The location is meant to be a volume that every replica of the app shares,
+// so that they all use one key ring. ASP.NET Core keeps the keys in it
+// unencrypted unless the app protects them itself.
+//
+// When no location is configured for a Production app that looks like it runs
+// on Kubernetes, a warning is written to out. Whether the app is restarted or
+// scaled out is not known, so KUBERNETES_SERVICE_HOST is only a guess that
+// the keys will be lost.
+func ConfigureDataProtection(out io.Writer) (map[string]string, error) {
+	if launch.DataProtectionConfigured() {
+		return map[string]string{}, nil
+	}
+
+	bindings, err := servicebindings.NewResolver().Resolve(BindingType, "", launch.PlatformDir())
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %q service bindings: %w", BindingType, err)
+	}
+
+	var path, source string
+	switch len(bindings) {
+	case 0:
+		path = os.Getenv("BPL_DOTNET_DATAPROTECTION_PATH")
+		source = "BPL_DOTNET_DATAPROTECTION_PATH"
+	case 1:
+		entry, ok := bindings[0].Entries[PathKey]
+		if !ok {
+			return nil, fmt.Errorf("service binding %q has no %q entry naming a writable directory for the keys", bindings[0].Name, PathKey)
+		}
+
+		path, err = entry.ReadString()
+		if err != nil {
+			return nil, err
+		}
+		path = strings.TrimSpace(path)
+		source = fmt.Sprintf("service binding %q", bindings[0].Name)
+	default:
+		return nil, fmt.Errorf("found %d %q service bindings but expected at most 1", len(bindings), BindingType)
+	}
+
+	if path == "" {
+		// Kubernetes sets KUBERNETES_SERVICE_HOST in every container, where
+		// pods are often replicated and rescheduled.
+		if isProduction() && os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+			fmt.Fprintln(out, "WARNING: ASP.NET Core data protection keys may not be persisted")
+			fmt.Fprintln(out, "  The app appears to run on Kubernetes. Unless the app persists its keys itself, antiforgery tokens and auth cookies break when it restarts or scales out")
+			fmt.Fprintf(out, "  Set BPL_DOTNET_DATAPROTECTION_PATH to a shared volume or add a %q service binding\n", BindingType)
+		}
+
+		return map[string]string{}, nil
+	}
+
+	keyDir := launch.DataProtectionKeyDir(path)
+	err = os.MkdirAll(keyDir, os.ModePerm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the data protection key directory from %s: %w", source, err)
+	}
+
+	fmt.Fprintf(out, "Persisting data protection keys to %s from %s\n", keyDir, source)
+
+	return launch.DataProtectionEnv(path), nil
+}
+
+// isProduction mirrors how ASP.NET Core picks its environment, which is
+// Production unless it is set otherwise.
+func isProduction() bool {
+	environment := os.Getenv("ASPNETCORE_ENVIRONMENT")
+	if environment == "" {
+		environment = os.Getenv("DOTNET_ENVIRONMENT")
+	}
+
+	return environment == "" || strings.EqualFold(environment, "Production")
+}
@@ -0,0 +1,216 @@
+package internal_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/dotnet-execute/cmd/data-protection/internal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testConfigureDataProtection(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		bindingRoot string
+		keysDir     string
+		buffer      *bytes.Buffer
+	)
+
+	it.Before(func() {
+		var err error
+		bindingRoot, err = os.MkdirTemp("", "bindings")
+		Expect(err).NotTo(HaveOccurred())
+
+		keysDir, err = os.MkdirTemp("", "keys")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.Setenv("SERVICE_BINDING_ROOT", bindingRoot)).To(Succeed())
+
+		buffer = bytes.NewBuffer(nil)
+	})
+
+	it.After(func() {
+		for _, name := range []string{
+			"SERVICE_BINDING_ROOT",
+			"CNB_PLATFORM_DIR",
+			"BPL_DOTNET_DATAPROTECTION_PATH",
+			"DataProtection__KeyPath",
+			"LOCALAPPDATA",
+			"ASPNETCORE_ENVIRONMENT",
+			"DOTNET_ENVIRONMENT",
+			"KUBERNETES_SERVICE_HOST",
+		} {
+			Expect(os.Unsetenv(name)).To(Succeed())
+		}
+
+		Expect(os.RemoveAll(bindingRoot)).To(Succeed())
+		Expect(os.RemoveAll(keysDir)).To(Succeed())
+	})
+
+	context("when BPL_DOTNET_DATAPROTECTION_PATH is set", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BPL_DOTNET_DATAPROTECTION_PATH", filepath.Join(keysDir, "app"))).To(Succeed())
+		})
+
+		it("persists the keys there", func() {
+			envVars, err := internal.ConfigureDataProtection(buffer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envVars).To(Equal(map[string]string{
+				"LOCALAPPDATA":            filepath.Join(keysDir, "app"),
+				"DataProtection__KeyPath": filepath.Join(keysDir, "app", "ASP.NET", "DataProtection-Keys"),
+			}))
+			Expect(filepath.Join(keysDir, "app", "ASP.NET", "DataProtection-Keys")).To(BeADirectory())
+			Expect(buffer.String()).To(ContainSubstring("from BPL_DOTNET_DATAPROTECTION_PATH"))
+		})
+
+		context("when DataProtection__KeyPath is already set", func() {
+			it.Before(func() {
+				Expect(os.Setenv("DataProtection__KeyPath", "/keys")).To(Succeed())
+			})
+
+			it("does nothing", func() {
+				envVars, err := internal.ConfigureDataProtection(buffer)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(envVars).To(BeEmpty())
+			})
+		})
+
+		context("when LOCALAPPDATA is already set", func() {
+			it.Before(func() {
+				Expect(os.Setenv("LOCALAPPDATA", "/data")).To(Succeed())
+			})
+
+			it("does nothing", func() {
+				envVars, err := internal.ConfigureDataProtection(buffer)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(envVars).To(BeEmpty())
+			})
+		})
+	})
+
+	context("when there is a dataprotection binding", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BPL_DOTNET_DATAPROTECTION_PATH", "/ignored")).To(Succeed())
+
+			Expect(os.MkdirAll(filepath.Join(bindingRoot, "keys"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(bindingRoot, "keys", "type"), []byte("dataprotection"), 0600)).To(Succeed())
+		})
+
+		context("when the binding has a path entry", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(bindingRoot, "keys", "path"), []byte(keysDir+"\n"), 0600)).To(Succeed())
+			})
+
+			it("persists the keys to that path", func() {
+				envVars, err := internal.ConfigureDataProtection(buffer)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(envVars).To(Equal(map[string]string{
+					"LOCALAPPDATA":            keysDir,
+					"DataProtection__KeyPath": filepath.Join(keysDir, "ASP.NET", "DataProtection-Keys"),
+				}))
+				Expect(filepath.Join(keysDir, "ASP.NET", "DataProtection-Keys")).To(BeADirectory())
+				Expect(buffer.String()).To(ContainSubstring(`from service binding "keys"`))
+			})
+		})
+	})
+
+	context("when no key location is configured", func() {
+		it("does nothing", func() {
+			envVars, err := internal.ConfigureDataProtection(buffer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envVars).To(BeEmpty())
+			Expect(buffer.String()).To(BeEmpty())
+		})
+
+		context("when a Production app runs on Kubernetes", func() {
+			it.Before(func() {
+				Expect(os.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")).To(Succeed())
+			})
+
+			it("warns that the keys may not be persisted", func() {
+				envVars, err := internal.ConfigureDataProtection(buffer)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(envVars).To(BeEmpty())
+				Expect(buffer.String()).To(ContainSubstring("WARNING: ASP.NET Core data protection keys may not be persisted"))
+				Expect(buffer.String()).To(ContainSubstring(`Set BPL_DOTNET_DATAPROTECTION_PATH to a shared volume or add a "dataprotection" service binding`))
+			})
+
+			context("when the app is not in Production", func() {
+				it.Before(func() {
+					Expect(os.Setenv("ASPNETCORE_ENVIRONMENT", "Development")).To(Succeed())
+				})
+
+				it("does not warn", func() {
+					_, err := internal.ConfigureDataProtection(buffer)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(buffer.String()).To(BeEmpty())
+				})
+			})
+		})
+	})
+
+	context("when SERVICE_BINDING_ROOT is not set", func() {
+		it.Before(func() {
+			Expect(os.Unsetenv("SERVICE_BINDING_ROOT")).To(Succeed())
+			Expect(os.Setenv("CNB_PLATFORM_DIR", bindingRoot)).To(Succeed())
+
+			Expect(os.MkdirAll(filepath.Join(bindingRoot, "bindings", "keys"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(bindingRoot, "bindings", "keys", "type"), []byte("dataprotection"), 0600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(bindingRoot, "bindings", "keys", "path"), []byte(keysDir), 0600)).To(Succeed())
+		})
+
+		it("reads the bindings from CNB_PLATFORM_DIR", func() {
+			envVars, err := internal.ConfigureDataProtection(buffer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envVars).To(Equal(map[string]string{
+				"LOCALAPPDATA":            keysDir,
+				"DataProtection__KeyPath": filepath.Join(keysDir, "ASP.NET", "DataProtection-Keys"),
+			}))
+		})
+	})
+
+	context("failure cases", func() {
+		context("when there is more than one dataprotection binding", func() {
+			it.Before(func() {
+				for _, name := range []string{"first", "second"} {
+					Expect(os.MkdirAll(filepath.Join(bindingRoot, name), os.ModePerm)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(bindingRoot, name, "type"), []byte("dataprotection"), 0600)).To(Succeed())
+				}
+			})
+
+			it("returns an error", func() {
+				_, err := internal.ConfigureDataProtection(buffer)
+				Expect(err).To(MatchError(`found 2 "dataprotection" service bindings but expected at most 1`))
+			})
+		})
+
+		context("when the binding has no path entry", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(bindingRoot, "keys"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(bindingRoot, "keys", "type"), []byte("dataprotection"), 0600)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := internal.ConfigureDataProtection(buffer)
+				Expect(err).To(MatchError(`service binding "keys" has no "path" entry naming a writable directory for the keys`))
+			})
+		})
+
+		context("when the key directory can not be created", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(keysDir, "file"), nil, 0600)).To(Succeed())
+				Expect(os.Setenv("BPL_DOTNET_DATAPROTECTION_PATH", filepath.Join(keysDir, "file", "keys"))).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := internal.ConfigureDataProtection(buffer)
+				Expect(err).To(MatchError(ContainSubstring("failed to create the data protection key directory from BPL_DOTNET_DATAPROTECTION_PATH")))
+			})
+		})
+	})
+}
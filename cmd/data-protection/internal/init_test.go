@@ -0,0 +1,14 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitDataProtection(t *testing.T) {
+	suite := spec.New("data-protection", spec.Report(report.Terminal{}), spec.Sequential())
+	suite("ConfigureDataProtection", testConfigureDataProtection)
+	suite.Run(t)
+}
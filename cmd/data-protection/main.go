@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/paketo-buildpacks/dotnet-execute/cmd/data-protection/internal"
+)
+
+// main will point ASP.NET Core data protection at a persisted key directory,
+// and write the resulting environment variables to FD 3.
+// See https://github.com/buildpacks/rfcs/blob/main/text/0093-remove-shell-processes.md.
+func main() {
+	execdWriter := os.NewFile(3, "/dev/fd/3")
+
+	envVars, err := internal.ConfigureDataProtection(os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	for k, v := range envVars {
+		if _, err := fmt.Fprintf(execdWriter, "%s=%s\n", k, strconv.Quote(v)); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DiagnosticPorts configures the runtime's diagnostic port as
+	// <address>[,connect|listen][,suspend|nosuspend].
+	DiagnosticPorts = "DOTNET_DiagnosticPorts"
+
+	// EnableDiagnostics=0 turns off the debugger, profiler and diagnostic IPC
+	// in the runtime.
+	EnableDiagnostics = "DOTNET_EnableDiagnostics"
+
+	// DefaultSocketPath is where the diagnostic port socket lives when
+	// BPL_DOTNET_DIAGNOSTIC_PORT=true. It matches the path used in the
+	// dotnet-monitor sidecar examples.
+	DefaultSocketPath = "/diag/dotnet-monitor.sock"
+)
+
+// ConfigureDiagnosticPort returns the environment variables that set up a
+// diagnostic port for a tool such as dotnet-monitor. BPL_DOTNET_DIAGNOSTIC_PORT
+// is either true or the path of the socket, BPL_DOTNET_DIAGNOSTIC_PORT_MODE is
+// connect (the default) or listen, and BPL_DOTNET_DIAGNOSTIC_PORT_SUSPEND
+// decides whether the runtime waits for the tool before starting the app. If
+// DOTNET_DiagnosticPorts is already set, no further action is taken.
+//
+// When DOTNET_EnableDiagnostics=0, the port is not configured and every
+// diagnostic channel is turned off, regardless of the other settings.
+func ConfigureDiagnosticPort() (map[string]string, error) {
+	if os.Getenv(EnableDiagnostics) == "0" {
+		fmt.Println("Diagnostics are disabled by DOTNET_EnableDiagnostics=0")
+		return map[string]string{
+			DiagnosticPorts:                     "",
+			"DOTNET_EnableDiagnostics_IPC":      "0",
+			"DOTNET_EnableDiagnostics_Debugger": "0",
+			"DOTNET_EnableDiagnostics_Profiler": "0",
+		}, nil
+	}
+
+	if _, ok := os.LookupEnv(DiagnosticPorts); ok {
+		return map[string]string{}, nil
+	}
+
+	path := os.Getenv("BPL_DOTNET_DIAGNOSTIC_PORT")
+	if enabled, err := strconv.ParseBool(path); err == nil {
+		if !enabled {
+			return map[string]string{}, nil
+		}
+		path = DefaultSocketPath
+	}
+
+	if path == "" {
+		return map[string]string{}, nil
+	}
+
+	if !filepath.IsAbs(path) {
+		return nil, fmt.Errorf("invalid BPL_DOTNET_DIAGNOSTIC_PORT %q: expected true or an absolute socket path", path)
+	}
+
+	mode := "connect"
+	if value := os.Getenv("BPL_DOTNET_DIAGNOSTIC_PORT_MODE"); value != "" {
+		mode = strings.ToLower(value)
+	}
+
+	if mode != "connect" && mode != "listen" {
+		return nil, fmt.Errorf("invalid BPL_DOTNET_DIAGNOSTIC_PORT_MODE %q: expected connect or listen", mode)
+	}
+
+	suspend := "nosuspend"
+	if value := os.Getenv("BPL_DOTNET_DIAGNOSTIC_PORT_SUSPEND"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BPL_DOTNET_DIAGNOSTIC_PORT_SUSPEND %q: %w", value, err)
+		}
+
+		if enabled {
+			suspend = "suspend"
+		}
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the diagnostic port directory: %w", err)
+	}
+
+	port := strings.Join([]string{path, mode, suspend}, ",")
+	fmt.Printf("Setting %s=%s\n", DiagnosticPorts, port)
+
+	return map[string]string{
+		DiagnosticPorts: port,
+	}, nil
+}
@@ -0,0 +1,163 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/dotnet-execute/cmd/diagnostic-port/internal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testConfigureDiagnosticPort(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		diagDir string
+	)
+
+	it.Before(func() {
+		var err error
+		diagDir, err = os.MkdirTemp("", "diag")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.Setenv("BPL_DOTNET_DIAGNOSTIC_PORT", filepath.Join(diagDir, "shared", "monitor.sock"))).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.Unsetenv("BPL_DOTNET_DIAGNOSTIC_PORT")).To(Succeed())
+		Expect(os.Unsetenv("BPL_DOTNET_DIAGNOSTIC_PORT_MODE")).To(Succeed())
+		Expect(os.Unsetenv("BPL_DOTNET_DIAGNOSTIC_PORT_SUSPEND")).To(Succeed())
+		Expect(os.Unsetenv("DOTNET_DiagnosticPorts")).To(Succeed())
+		Expect(os.Unsetenv("DOTNET_EnableDiagnostics")).To(Succeed())
+
+		Expect(os.RemoveAll(diagDir)).To(Succeed())
+	})
+
+	it("connects to the socket without suspending the app", func() {
+		envVars, err := internal.ConfigureDiagnosticPort()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(envVars).To(Equal(map[string]string{
+			"DOTNET_DiagnosticPorts": filepath.Join(diagDir, "shared", "monitor.sock") + ",connect,nosuspend",
+		}))
+		Expect(filepath.Join(diagDir, "shared")).To(BeADirectory())
+	})
+
+	context("when the mode and suspend settings are set", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BPL_DOTNET_DIAGNOSTIC_PORT_MODE", "Listen")).To(Succeed())
+			Expect(os.Setenv("BPL_DOTNET_DIAGNOSTIC_PORT_SUSPEND", "true")).To(Succeed())
+		})
+
+		it("uses them", func() {
+			envVars, err := internal.ConfigureDiagnosticPort()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envVars).To(Equal(map[string]string{
+				"DOTNET_DiagnosticPorts": filepath.Join(diagDir, "shared", "monitor.sock") + ",listen,suspend",
+			}))
+		})
+	})
+
+	context("when BPL_DOTNET_DIAGNOSTIC_PORT is not set", func() {
+		it.Before(func() {
+			Expect(os.Unsetenv("BPL_DOTNET_DIAGNOSTIC_PORT")).To(Succeed())
+		})
+
+		it("does nothing", func() {
+			envVars, err := internal.ConfigureDiagnosticPort()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envVars).To(BeEmpty())
+		})
+	})
+
+	context("when BPL_DOTNET_DIAGNOSTIC_PORT is false", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BPL_DOTNET_DIAGNOSTIC_PORT", "false")).To(Succeed())
+		})
+
+		it("does nothing", func() {
+			envVars, err := internal.ConfigureDiagnosticPort()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envVars).To(BeEmpty())
+		})
+	})
+
+	context("when DOTNET_DiagnosticPorts is already set", func() {
+		it.Before(func() {
+			Expect(os.Setenv("DOTNET_DiagnosticPorts", "/some/socket")).To(Succeed())
+		})
+
+		it("does nothing", func() {
+			envVars, err := internal.ConfigureDiagnosticPort()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envVars).To(BeEmpty())
+		})
+	})
+
+	context("when DOTNET_EnableDiagnostics=0", func() {
+		it.Before(func() {
+			Expect(os.Setenv("DOTNET_EnableDiagnostics", "0")).To(Succeed())
+			Expect(os.Setenv("DOTNET_DiagnosticPorts", "/some/socket")).To(Succeed())
+		})
+
+		it("turns every diagnostic channel off", func() {
+			envVars, err := internal.ConfigureDiagnosticPort()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envVars).To(Equal(map[string]string{
+				"DOTNET_DiagnosticPorts":            "",
+				"DOTNET_EnableDiagnostics_IPC":      "0",
+				"DOTNET_EnableDiagnostics_Debugger": "0",
+				"DOTNET_EnableDiagnostics_Profiler": "0",
+			}))
+			Expect(filepath.Join(diagDir, "shared")).NotTo(BeADirectory())
+		})
+	})
+
+	context("failure cases", func() {
+		context("when the socket path is relative", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BPL_DOTNET_DIAGNOSTIC_PORT", "diag/monitor.sock")).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := internal.ConfigureDiagnosticPort()
+				Expect(err).To(MatchError(`invalid BPL_DOTNET_DIAGNOSTIC_PORT "diag/monitor.sock": expected true or an absolute socket path`))
+			})
+		})
+
+		context("when the mode is unknown", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BPL_DOTNET_DIAGNOSTIC_PORT_MODE", "both")).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := internal.ConfigureDiagnosticPort()
+				Expect(err).To(MatchError(`invalid BPL_DOTNET_DIAGNOSTIC_PORT_MODE "both": expected connect or listen`))
+			})
+		})
+
+		context("when the suspend setting is not a boolean", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BPL_DOTNET_DIAGNOSTIC_PORT_SUSPEND", "maybe")).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := internal.ConfigureDiagnosticPort()
+				Expect(err).To(MatchError(ContainSubstring(`invalid BPL_DOTNET_DIAGNOSTIC_PORT_SUSPEND "maybe"`)))
+			})
+		})
+
+		context("when the socket directory can not be created", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(diagDir, "shared"), nil, 0600)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := internal.ConfigureDiagnosticPort()
+				Expect(err).To(MatchError(ContainSubstring("failed to create the diagnostic port directory")))
+			})
+		})
+	})
+}
@@ -0,0 +1,14 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitDiagnosticPort(t *testing.T) {
+	suite := spec.New("diagnostic-port", spec.Report(report.Terminal{}), spec.Sequential())
+	suite("ConfigureDiagnosticPort", testConfigureDiagnosticPort)
+	suite.Run(t)
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/paketo-buildpacks/dotnet-execute/cmd/diagnostic-port/internal"
+)
+
+// main will configure the diagnostic port when BPL_DOTNET_DIAGNOSTIC_PORT is
+// set, and write the resulting environment variables to FD 3. A problem with
+// the configuration is reported, but does not stop the app from starting.
+// See https://github.com/buildpacks/rfcs/blob/main/text/0093-remove-shell-processes.md.
+func main() {
+	execdWriter := os.NewFile(3, "/dev/fd/3")
+
+	envVars, err := internal.ConfigureDiagnosticPort()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "The diagnostic port is not configured: %s\n", err)
+		return
+	}
+
+	for k, v := range envVars {
+		if _, err := fmt.Fprintf(execdWriter, "%s=%s\n", k, strconv.Quote(v)); err != nil {
+			return
+		}
+	}
+}
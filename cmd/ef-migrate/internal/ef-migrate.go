@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/paketo-buildpacks/dotnet-execute/cmd/internal/launch"
+	"github.com/paketo-buildpacks/packit/v2/servicebindings"
+)
+
+const (
+	// BindingType is the type of the service binding that holds the database
+	// connection string for the migration bundle.
+	BindingType = "efcore"
+
+	// ConnectionStringKey is the binding entry that holds the connection
+	// string.
+	ConnectionStringKey = "connection-string"
+)
+
+// MigrationCommand builds the command line and the environment that run an
+// EF Core migration bundle in environ. It expects arguments of the form
+//
+//	--connection-env <NAME> -- <bundle> [args...]
+//
+// The connection string is taken from an "efcore" service binding when there
+// is one, and from the environment variable NAME otherwise. When neither is
+// set, the bundle falls back to the connection string configured in the app.
+//
+// The connection string is a secret, so it is not passed to the bundle with
+// --connection, where any process could read it from the command line. The
+// bundle gets it in NAME instead, and reads it through the app's
+// configuration, so NAME has to be the configuration key that the app's
+// DbContext takes its connection string from.
+func MigrationCommand(args, environ []string) ([]string, []string, error) {
+	if len(args) < 4 || args[0] != "--connection-env" || args[2] != "--" {
+		return nil, nil, errors.New("usage: ef-migrate --connection-env <NAME> -- <bundle> [args...]")
+	}
+
+	connectionEnv := args[1]
+	command := append([]string{}, args[3:]...)
+
+	bindings, err := servicebindings.NewResolver().Resolve(BindingType, "", launch.PlatformDir())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve %q service bindings: %w", BindingType, err)
+	}
+
+	switch len(bindings) {
+	case 0:
+		if connection, ok := lookupEnv(environ, connectionEnv); ok && connection != "" {
+			fmt.Printf("Using EF Core connection string from $%s\n", connectionEnv)
+		} else {
+			fmt.Println("No EF Core connection string configured, using the app's configuration")
+		}
+
+		return command, environ, nil
+
+	case 1:
+		entry, ok := bindings[0].Entries[ConnectionStringKey]
+		if !ok {
+			return nil, nil, fmt.Errorf("service binding %q has no %q entry", bindings[0].Name, ConnectionStringKey)
+		}
+
+		connection, err := entry.ReadString()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		fmt.Printf("Using EF Core connection string from service binding %q as $%s\n", bindings[0].Name, connectionEnv)
+
+		var env []string
+		for _, variable := range environ {
+			if name, _, _ := strings.Cut(variable, "="); name != connectionEnv {
+				env = append(env, variable)
+			}
+		}
+
+		return command, append(env, fmt.Sprintf("%s=%s", connectionEnv, connection)), nil
+
+	default:
+		return nil, nil, fmt.Errorf("found %d %q service bindings but expected at most 1", len(bindings), BindingType)
+	}
+}
+
+func lookupEnv(environ []string, name string) (string, bool) {
+	for _, variable := range environ {
+		if key, value, ok := strings.Cut(variable, "="); ok && key == name {
+			return value, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,126 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/dotnet-execute/cmd/ef-migrate/internal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testMigrationCommand(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		bindingRoot string
+		environ     []string
+	)
+
+	it.Before(func() {
+		var err error
+		bindingRoot, err = os.MkdirTemp("", "bindings")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.Setenv("SERVICE_BINDING_ROOT", bindingRoot)).To(Succeed())
+		environ = []string{"PATH=/usr/bin"}
+	})
+
+	it.After(func() {
+		Expect(os.Unsetenv("SERVICE_BINDING_ROOT")).To(Succeed())
+		Expect(os.Unsetenv("CNB_PLATFORM_DIR")).To(Succeed())
+		Expect(os.RemoveAll(bindingRoot)).To(Succeed())
+	})
+
+	context("when no connection string is configured", func() {
+		it("runs the bundle as is", func() {
+			command, env, err := internal.MigrationCommand([]string{"--connection-env", "SOME_CONNECTION", "--", "/workspace/efbundle", "--verbose"}, environ)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(command).To(Equal([]string{"/workspace/efbundle", "--verbose"}))
+			Expect(env).To(Equal([]string{"PATH=/usr/bin"}))
+		})
+	})
+
+	context("when the environment variable is set", func() {
+		it.Before(func() {
+			environ = append(environ, "SOME_CONNECTION=Host=env")
+		})
+
+		it("leaves the connection string in the environment of the bundle", func() {
+			command, env, err := internal.MigrationCommand([]string{"--connection-env", "SOME_CONNECTION", "--", "/workspace/efbundle"}, environ)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(command).To(Equal([]string{"/workspace/efbundle"}))
+			Expect(env).To(Equal([]string{"PATH=/usr/bin", "SOME_CONNECTION=Host=env"}))
+		})
+
+		context("and there is an efcore binding", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(bindingRoot, "db"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(bindingRoot, "db", "type"), []byte("efcore"), 0600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(bindingRoot, "db", "connection-string"), []byte("Host=binding"), 0600)).To(Succeed())
+			})
+
+			it("replaces it with the binding in the environment of the bundle", func() {
+				command, env, err := internal.MigrationCommand([]string{"--connection-env", "SOME_CONNECTION", "--", "/workspace/efbundle"}, environ)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(command).To(Equal([]string{"/workspace/efbundle"}))
+				Expect(env).To(Equal([]string{"PATH=/usr/bin", "SOME_CONNECTION=Host=binding"}))
+			})
+		})
+	})
+
+	context("when SERVICE_BINDING_ROOT is not set", func() {
+		it.Before(func() {
+			Expect(os.Unsetenv("SERVICE_BINDING_ROOT")).To(Succeed())
+			Expect(os.Setenv("CNB_PLATFORM_DIR", bindingRoot)).To(Succeed())
+
+			Expect(os.MkdirAll(filepath.Join(bindingRoot, "bindings", "db"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(bindingRoot, "bindings", "db", "type"), []byte("efcore"), 0600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(bindingRoot, "bindings", "db", "connection-string"), []byte("Host=platform"), 0600)).To(Succeed())
+		})
+
+		it("reads the bindings from CNB_PLATFORM_DIR", func() {
+			command, env, err := internal.MigrationCommand([]string{"--connection-env", "SOME_CONNECTION", "--", "/workspace/efbundle"}, environ)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(command).To(Equal([]string{"/workspace/efbundle"}))
+			Expect(env).To(Equal([]string{"PATH=/usr/bin", "SOME_CONNECTION=Host=platform"}))
+		})
+	})
+
+	context("failure cases", func() {
+		context("when the arguments are malformed", func() {
+			it("returns an error", func() {
+				_, _, err := internal.MigrationCommand([]string{"/workspace/efbundle"}, environ)
+				Expect(err).To(MatchError(ContainSubstring("usage: ef-migrate")))
+			})
+		})
+
+		context("when the binding has no connection string", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(bindingRoot, "db"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(bindingRoot, "db", "type"), []byte("efcore"), 0600)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, _, err := internal.MigrationCommand([]string{"--connection-env", "SOME_CONNECTION", "--", "/workspace/efbundle"}, environ)
+				Expect(err).To(MatchError(`service binding "db" has no "connection-string" entry`))
+			})
+		})
+
+		context("when there are multiple efcore bindings", func() {
+			it.Before(func() {
+				for _, name := range []string{"db1", "db2"} {
+					Expect(os.MkdirAll(filepath.Join(bindingRoot, name), os.ModePerm)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(bindingRoot, name, "type"), []byte("efcore"), 0600)).To(Succeed())
+				}
+			})
+
+			it("returns an error", func() {
+				_, _, err := internal.MigrationCommand([]string{"--connection-env", "SOME_CONNECTION", "--", "/workspace/efbundle"}, environ)
+				Expect(err).To(MatchError(`found 2 "efcore" service bindings but expected at most 1`))
+			})
+		})
+	})
+}
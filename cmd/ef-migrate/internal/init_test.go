@@ -0,0 +1,14 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitEFMigrate(t *testing.T) {
+	suite := spec.New("ef-migrate", spec.Report(report.Terminal{}), spec.Sequential())
+	suite("MigrationCommand", testMigrationCommand)
+	suite.Run(t)
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/paketo-buildpacks/dotnet-execute/cmd/ef-migrate/internal"
+)
+
+// main will run an EF Core migration bundle, passing it the connection string
+// from a service binding or environment variable in its environment.
+func main() {
+	command, env, err := internal.MigrationCommand(os.Args[1:], os.Environ())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	err = syscall.Exec(command[0], command, env)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to run %s: %s\n", command[0], err)
+		os.Exit(1)
+	}
+}
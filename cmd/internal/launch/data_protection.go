@@ -0,0 +1,48 @@
+package launch
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const (
+	// LocalAppData is the variable that ASP.NET Core builds its default data
+	// protection key directory from on Linux, before it falls back to HOME.
+	// Unlike HOME, nothing else in .NET on Linux reads it.
+	LocalAppData = "LOCALAPPDATA"
+
+	// DataProtectionKeyPath is the configuration key, in environment variable
+	// form, that apps can pass to PersistKeysToFileSystem. ASP.NET Core does
+	// not read it on its own.
+	DataProtectionKeyPath = "DataProtection__KeyPath"
+)
+
+// DataProtectionKeyDir returns the directory that ASP.NET Core keeps its data
+// protection keys in when LOCALAPPDATA is dir and the app does not configure
+// one.
+func DataProtectionKeyDir(dir string) string {
+	return filepath.Join(dir, "ASP.NET", "DataProtection-Keys")
+}
+
+// DataProtectionEnv returns the environment variables that keep ASP.NET Core
+// data protection keys under dir, both for apps that use the default key
+// directory and for apps that pass DataProtection__KeyPath to
+// PersistKeysToFileSystem.
+func DataProtectionEnv(dir string) map[string]string {
+	return map[string]string{
+		LocalAppData:          dir,
+		DataProtectionKeyPath: DataProtectionKeyDir(dir),
+	}
+}
+
+// DataProtectionConfigured reports whether the key location is already set,
+// by the app or by an earlier launch helper.
+func DataProtectionConfigured() bool {
+	for _, name := range []string{LocalAppData, DataProtectionKeyPath} {
+		if _, ok := os.LookupEnv(name); ok {
+			return true
+		}
+	}
+
+	return false
+}
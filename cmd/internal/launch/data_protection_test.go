@@ -0,0 +1,48 @@
+package launch_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/paketo-buildpacks/dotnet-execute/cmd/internal/launch"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testDataProtection(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	it.After(func() {
+		Expect(os.Unsetenv("LOCALAPPDATA")).To(Succeed())
+		Expect(os.Unsetenv("DataProtection__KeyPath")).To(Succeed())
+	})
+
+	it("keeps the keys where ASP.NET Core looks for them by default", func() {
+		Expect(launch.DataProtectionEnv("/keys")).To(Equal(map[string]string{
+			"LOCALAPPDATA":            "/keys",
+			"DataProtection__KeyPath": "/keys/ASP.NET/DataProtection-Keys",
+		}))
+		Expect(launch.DataProtectionConfigured()).To(BeFalse())
+	})
+
+	context("when LOCALAPPDATA is set", func() {
+		it.Before(func() {
+			Expect(os.Setenv("LOCALAPPDATA", "/data")).To(Succeed())
+		})
+
+		it("reports the keys as configured", func() {
+			Expect(launch.DataProtectionConfigured()).To(BeTrue())
+		})
+	})
+
+	context("when DataProtection__KeyPath is set", func() {
+		it.Before(func() {
+			Expect(os.Setenv("DataProtection__KeyPath", "/keys")).To(Succeed())
+		})
+
+		it("reports the keys as configured", func() {
+			Expect(launch.DataProtectionConfigured()).To(BeTrue())
+		})
+	})
+}
@@ -0,0 +1,16 @@
+package launch_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitLaunch(t *testing.T) {
+	suite := spec.New("launch", spec.Report(report.Terminal{}), spec.Sequential())
+	suite("CheckWritable", testCheckWritable)
+	suite("DataProtection", testDataProtection)
+	suite("PlatformDir", testPlatformDir)
+	suite.Run(t)
+}
@@ -0,0 +1,33 @@
+// Package launch holds what the launch-time helpers in cmd share.
+package launch
+
+import (
+	"fmt"
+	"os"
+)
+
+// PlatformDir returns the platform directory whose bindings directory is used
+// when neither SERVICE_BINDING_ROOT nor CNB_BINDINGS is set.
+func PlatformDir() string {
+	if dir, ok := os.LookupEnv("CNB_PLATFORM_DIR"); ok && dir != "" {
+		return dir
+	}
+	return "/platform"
+}
+
+// CheckWritable creates dir when it does not exist and checks that a file
+// can be written to it.
+func CheckWritable(dir string) error {
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("%s can not be created: %w", dir, err)
+	}
+
+	file, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", dir, err)
+	}
+
+	_ = file.Close()
+	return os.Remove(file.Name())
+}
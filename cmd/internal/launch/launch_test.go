@@ -0,0 +1,74 @@
+package launch_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/dotnet-execute/cmd/internal/launch"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testCheckWritable(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		dir string
+	)
+
+	it.Before(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "writable")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.Chmod(dir, os.ModePerm)).To(Succeed())
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	it("creates the directory and leaves no file behind", func() {
+		Expect(launch.CheckWritable(filepath.Join(dir, "some", "dir"))).To(Succeed())
+
+		entries, err := os.ReadDir(filepath.Join(dir, "some", "dir"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+
+	context("failure cases", func() {
+		context("when the directory can not be created", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(dir, "some"), nil, 0600)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				err := launch.CheckWritable(filepath.Join(dir, "some", "dir"))
+				Expect(err).To(MatchError(ContainSubstring("can not be created")))
+			})
+		})
+	})
+}
+
+func testPlatformDir(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	it.After(func() {
+		Expect(os.Unsetenv("CNB_PLATFORM_DIR")).To(Succeed())
+	})
+
+	it("defaults to /platform", func() {
+		Expect(launch.PlatformDir()).To(Equal("/platform"))
+	})
+
+	context("when CNB_PLATFORM_DIR is set", func() {
+		it.Before(func() {
+			Expect(os.Setenv("CNB_PLATFORM_DIR", "/some/platform")).To(Succeed())
+		})
+
+		it("returns it", func() {
+			Expect(launch.PlatformDir()).To(Equal("/some/platform"))
+		})
+	})
+}
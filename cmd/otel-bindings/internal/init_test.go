@@ -0,0 +1,14 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitOTelBindings(t *testing.T) {
+	suite := spec.New("otel-bindings", spec.Report(report.Terminal{}), spec.Sequential())
+	suite("BindingEnvironment", testBindingEnvironment)
+	suite.Run(t)
+}
@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/paketo-buildpacks/dotnet-execute/cmd/internal/launch"
+	"github.com/paketo-buildpacks/packit/v2/servicebindings"
+)
+
+// BindingType is the type of the service binding that holds OpenTelemetry
+// exporter settings.
+const BindingType = "opentelemetry"
+
+// BindingEnvironment returns the OTEL_* entries of an "opentelemetry" service
+// binding, such as OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_SERVICE_NAME, as
+// environment variables. Variables that are already set are left alone, so
+// that the app's own configuration wins over the binding.
+func BindingEnvironment() (map[string]string, error) {
+	bindings, err := servicebindings.NewResolver().Resolve(BindingType, "", launch.PlatformDir())
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %q service bindings: %w", BindingType, err)
+	}
+
+	switch len(bindings) {
+	case 0:
+		return map[string]string{}, nil
+	case 1:
+	default:
+		return nil, fmt.Errorf("found %d %q service bindings but expected at most 1", len(bindings), BindingType)
+	}
+
+	var names []string
+	for name := range bindings[0].Entries {
+		if strings.HasPrefix(name, "OTEL_") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	envVars := map[string]string{}
+	for _, name := range names {
+		if _, ok := os.LookupEnv(name); ok {
+			continue
+		}
+
+		value, err := bindings[0].Entries[name].ReadString()
+		if err != nil {
+			return nil, err
+		}
+
+		fmt.Printf("Setting %s from service binding %q\n", name, bindings[0].Name)
+		envVars[name] = strings.TrimSpace(value)
+	}
+
+	return envVars, nil
+}
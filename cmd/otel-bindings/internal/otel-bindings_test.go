@@ -0,0 +1,111 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/dotnet-execute/cmd/otel-bindings/internal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testBindingEnvironment(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		bindingRoot string
+	)
+
+	it.Before(func() {
+		var err error
+		bindingRoot, err = os.MkdirTemp("", "bindings")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.Setenv("SERVICE_BINDING_ROOT", bindingRoot)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.Unsetenv("SERVICE_BINDING_ROOT")).To(Succeed())
+		Expect(os.Unsetenv("CNB_PLATFORM_DIR")).To(Succeed())
+		Expect(os.Unsetenv("OTEL_SERVICE_NAME")).To(Succeed())
+		Expect(os.RemoveAll(bindingRoot)).To(Succeed())
+	})
+
+	context("when there is no binding", func() {
+		it("returns nothing", func() {
+			envVars, err := internal.BindingEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envVars).To(BeEmpty())
+		})
+	})
+
+	context("when there is an opentelemetry binding", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(bindingRoot, "collector"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(bindingRoot, "collector", "type"), []byte("opentelemetry"), 0600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(bindingRoot, "collector", "OTEL_EXPORTER_OTLP_ENDPOINT"), []byte("http://collector:4317\n"), 0600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(bindingRoot, "collector", "OTEL_SERVICE_NAME"), []byte("from-binding"), 0600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(bindingRoot, "collector", "password"), []byte("secret"), 0600)).To(Succeed())
+		})
+
+		it("returns its OTEL_* entries", func() {
+			envVars, err := internal.BindingEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envVars).To(Equal(map[string]string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4317",
+				"OTEL_SERVICE_NAME":           "from-binding",
+			}))
+		})
+
+		context("when a setting is already in the environment", func() {
+			it.Before(func() {
+				Expect(os.Setenv("OTEL_SERVICE_NAME", "from-env")).To(Succeed())
+			})
+
+			it("keeps the environment value", func() {
+				envVars, err := internal.BindingEnvironment()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(envVars).To(Equal(map[string]string{
+					"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4317",
+				}))
+			})
+		})
+	})
+
+	context("when SERVICE_BINDING_ROOT is not set", func() {
+		it.Before(func() {
+			Expect(os.Unsetenv("SERVICE_BINDING_ROOT")).To(Succeed())
+			Expect(os.Setenv("CNB_PLATFORM_DIR", bindingRoot)).To(Succeed())
+
+			Expect(os.MkdirAll(filepath.Join(bindingRoot, "bindings", "collector"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(bindingRoot, "bindings", "collector", "type"), []byte("opentelemetry"), 0600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(bindingRoot, "bindings", "collector", "OTEL_SERVICE_NAME"), []byte("from-platform"), 0600)).To(Succeed())
+		})
+
+		it("reads the bindings from CNB_PLATFORM_DIR", func() {
+			envVars, err := internal.BindingEnvironment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envVars).To(Equal(map[string]string{
+				"OTEL_SERVICE_NAME": "from-platform",
+			}))
+		})
+	})
+
+	context("failure cases", func() {
+		context("when there is more than one opentelemetry binding", func() {
+			it.Before(func() {
+				for _, name := range []string{"first", "second"} {
+					Expect(os.MkdirAll(filepath.Join(bindingRoot, name), os.ModePerm)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(bindingRoot, name, "type"), []byte("opentelemetry"), 0600)).To(Succeed())
+				}
+			})
+
+			it("returns an error", func() {
+				_, err := internal.BindingEnvironment()
+				Expect(err).To(MatchError(`found 2 "opentelemetry" service bindings but expected at most 1`))
+			})
+		})
+	})
+}
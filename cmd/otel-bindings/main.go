@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/paketo-buildpacks/dotnet-execute/cmd/otel-bindings/internal"
+)
+
+// main will read OpenTelemetry exporter settings from an "opentelemetry"
+// service binding, and write them as environment variables to FD 3. A
+// problem with the binding is reported, but does not stop the app from
+// starting.
+// See https://github.com/buildpacks/rfcs/blob/main/text/0093-remove-shell-processes.md.
+func main() {
+	execdWriter := os.NewFile(3, "/dev/fd/3")
+
+	envVars, err := internal.BindingEnvironment()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "The OpenTelemetry service binding is ignored: %s\n", err)
+		return
+	}
+
+	for k, v := range envVars {
+		if _, err := fmt.Fprintf(execdWriter, "%s=%s\n", k, strconv.Quote(v)); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitReadOnlyRootFS(t *testing.T) {
+	suite := spec.New("readonly-rootfs", spec.Report(report.Terminal{}), spec.Sequential())
+	suite("ConfigureReadOnlyRootFS", testConfigureReadOnlyRootFS)
+	suite.Run(t)
+}
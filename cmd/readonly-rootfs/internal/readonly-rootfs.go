@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/paketo-buildpacks/dotnet-execute/cmd/internal/launch"
+)
+
+const (
+	// BundleExtractBaseDir is where single-file apps extract the files that
+	// can not be loaded from the bundle itself.
+	BundleExtractBaseDir = "DOTNET_BUNDLE_EXTRACT_BASE_DIR"
+
+	// TmpDir is the temporary directory used by the runtime and by
+	// Path.GetTempPath().
+	TmpDir = "TMPDIR"
+
+	// DefaultWritablePath is the volume used when BPL_DOTNET_WRITABLE_PATH is
+	// not set.
+	DefaultWritablePath = "/tmp"
+)
+
+// ConfigureReadOnlyRootFS returns the environment variables that move every
+// file the app writes at runtime onto a writable volume, when
+// BPL_DOTNET_READONLY_ROOTFS=true. The volume is BPL_DOTNET_WRITABLE_PATH.
+// Variables that are already set are left alone. Data protection keys are
+// kept under the volume the way the data-protection helper keeps them, unless
+// their location is already set.
+//
+// An error is returned when the volume is not writable, so that the app fails
+// at launch instead of when it first writes a file.
+func ConfigureReadOnlyRootFS() (map[string]string, error) {
+	enabled := false
+	if value := os.Getenv("BPL_DOTNET_READONLY_ROOTFS"); value != "" {
+		var err error
+		enabled, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BPL_DOTNET_READONLY_ROOTFS %q: %w", value, err)
+		}
+	}
+
+	if !enabled {
+		return map[string]string{}, nil
+	}
+
+	volume := DefaultWritablePath
+	if value := os.Getenv("BPL_DOTNET_WRITABLE_PATH"); value != "" {
+		volume = value
+	}
+
+	checkWritable := func(dir string) error {
+		err := launch.CheckWritable(dir)
+		if err != nil {
+			return fmt.Errorf("%w\nBPL_DOTNET_READONLY_ROOTFS=true needs a writable volume: mount one, such as an emptyDir, at %s or set BPL_DOTNET_WRITABLE_PATH to the path of one", err, volume)
+		}
+		return nil
+	}
+
+	dirs := map[string]string{
+		TmpDir:               volume,
+		BundleExtractBaseDir: filepath.Join(volume, "bundle-extract"),
+	}
+
+	envVars := map[string]string{}
+	for name, dir := range dirs {
+		if _, ok := os.LookupEnv(name); ok {
+			continue
+		}
+
+		err := checkWritable(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		fmt.Printf("Setting %s=%s\n", name, dir)
+		envVars[name] = dir
+	}
+
+	if !launch.DataProtectionConfigured() {
+		dataDir := filepath.Join(volume, "data-protection")
+		err := checkWritable(launch.DataProtectionKeyDir(dataDir))
+		if err != nil {
+			return nil, err
+		}
+
+		for name, value := range launch.DataProtectionEnv(dataDir) {
+			fmt.Printf("Setting %s=%s\n", name, value)
+			envVars[name] = value
+		}
+	}
+
+	return envVars, nil
+}
@@ -0,0 +1,116 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/dotnet-execute/cmd/readonly-rootfs/internal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testConfigureReadOnlyRootFS(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		volume string
+	)
+
+	it.Before(func() {
+		var err error
+		volume, err = os.MkdirTemp("", "volume")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.Setenv("BPL_DOTNET_READONLY_ROOTFS", "true")).To(Succeed())
+		Expect(os.Setenv("BPL_DOTNET_WRITABLE_PATH", volume)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.Unsetenv("BPL_DOTNET_READONLY_ROOTFS")).To(Succeed())
+		Expect(os.Unsetenv("BPL_DOTNET_WRITABLE_PATH")).To(Succeed())
+		Expect(os.Unsetenv("DataProtection__KeyPath")).To(Succeed())
+		Expect(os.Unsetenv("LOCALAPPDATA")).To(Succeed())
+		Expect(os.RemoveAll(volume)).To(Succeed())
+	})
+
+	it("points the writable paths at the volume", func() {
+		envVars, err := internal.ConfigureReadOnlyRootFS()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(envVars).To(Equal(map[string]string{
+			"TMPDIR":                         volume,
+			"DOTNET_BUNDLE_EXTRACT_BASE_DIR": filepath.Join(volume, "bundle-extract"),
+			"LOCALAPPDATA":                   filepath.Join(volume, "data-protection"),
+			"DataProtection__KeyPath":        filepath.Join(volume, "data-protection", "ASP.NET", "DataProtection-Keys"),
+		}))
+
+		Expect(filepath.Join(volume, "bundle-extract")).To(BeADirectory())
+		Expect(filepath.Join(volume, "data-protection", "ASP.NET", "DataProtection-Keys")).To(BeADirectory())
+	})
+
+	context("when a path is already set", func() {
+		it.Before(func() {
+			Expect(os.Setenv("DataProtection__KeyPath", "/keys")).To(Succeed())
+		})
+
+		it("leaves it alone", func() {
+			envVars, err := internal.ConfigureReadOnlyRootFS()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envVars).NotTo(HaveKey("DataProtection__KeyPath"))
+			Expect(envVars).NotTo(HaveKey("LOCALAPPDATA"))
+			Expect(envVars).To(HaveKey("TMPDIR"))
+		})
+	})
+
+	context("when LOCALAPPDATA is already set", func() {
+		it.Before(func() {
+			Expect(os.Setenv("LOCALAPPDATA", "/data")).To(Succeed())
+		})
+
+		it("leaves the data protection keys alone", func() {
+			envVars, err := internal.ConfigureReadOnlyRootFS()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envVars).NotTo(HaveKey("DataProtection__KeyPath"))
+			Expect(envVars).NotTo(HaveKey("LOCALAPPDATA"))
+		})
+	})
+
+	context("when BPL_DOTNET_READONLY_ROOTFS is not set", func() {
+		it.Before(func() {
+			Expect(os.Unsetenv("BPL_DOTNET_READONLY_ROOTFS")).To(Succeed())
+		})
+
+		it("does nothing", func() {
+			envVars, err := internal.ConfigureReadOnlyRootFS()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envVars).To(BeEmpty())
+		})
+	})
+
+	context("failure cases", func() {
+		context("when BPL_DOTNET_READONLY_ROOTFS is not a boolean", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BPL_DOTNET_READONLY_ROOTFS", "yes please")).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := internal.ConfigureReadOnlyRootFS()
+				Expect(err).To(MatchError(ContainSubstring(`invalid BPL_DOTNET_READONLY_ROOTFS "yes please"`)))
+			})
+		})
+
+		context("when the volume is not writable", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BPL_DOTNET_WRITABLE_PATH", filepath.Join(volume, "file", "volume"))).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(volume, "file"), nil, 0600)).To(Succeed())
+			})
+
+			it("returns an error with guidance", func() {
+				_, err := internal.ConfigureReadOnlyRootFS()
+				Expect(err).To(MatchError(ContainSubstring("can not be created")))
+				Expect(err).To(MatchError(ContainSubstring("BPL_DOTNET_READONLY_ROOTFS=true needs a writable volume: mount one, such as an emptyDir, at %s", filepath.Join(volume, "file", "volume"))))
+			})
+		})
+	})
+}
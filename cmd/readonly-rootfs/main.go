@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/paketo-buildpacks/dotnet-execute/cmd/readonly-rootfs/internal"
+)
+
+// main will point the paths that the app writes to at a writable volume when
+// BPL_DOTNET_READONLY_ROOTFS is set, and write the resulting environment
+// variables to FD 3. It fails the launch when the volume is not writable.
+// See https://github.com/buildpacks/rfcs/blob/main/text/0093-remove-shell-processes.md.
+func main() {
+	execdWriter := os.NewFile(3, "/dev/fd/3")
+
+	envVars, err := internal.ConfigureReadOnlyRootFS()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	for k, v := range envVars {
+		if _, err := fmt.Fprintf(execdWriter, "%s=%s\n", k, strconv.Quote(v)); err != nil {
+			return
+		}
+	}
+}
@@ -1,5 +1,24 @@
 package dotnetexecute
 
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultEFConnectionEnv is the environment variable that the migrate
+// process reads the database connection string from by default.
+const DefaultEFConnectionEnv = "ConnectionStrings__DefaultConnection"
+
+const (
+	// LiveReloadModeBinary reloads the published app with watchexec.
+	LiveReloadModeBinary = "binary"
+
+	// LiveReloadModeSource rebuilds and reloads the app from source with
+	// dotnet watch.
+	LiveReloadModeSource = "source"
+)
+
 // Configuration enumerates the environment variable configuration options
 // that govern the buildpack's behaviour.
 type Configuration struct {
@@ -18,12 +37,133 @@ type Configuration struct {
 	// reloadable process manager.
 	LiveReloadEnabled bool `env:"BP_LIVE_RELOAD_ENABLED"`
 
+	// BP_LIVE_RELOAD_MODE selects how live reload works. In the default
+	// "binary" mode, watchexec restarts the published app. In "source" mode,
+	// the app's project file is run with `dotnet watch run` and the .NET SDK
+	// is included in the app launch image.
+	LiveReloadMode string `env:"BP_LIVE_RELOAD_MODE,default=binary"`
+
+	// BP_LIVE_RELOAD_WATCH is a comma-separated list of patterns that decide
+	// which changes restart the app when live reload is enabled. Globs such
+	// as *.dll or src/**/*.cs are passed to watchexec as --filter, and a
+	// change that matches any of them restarts the app; any other entry is a
+	// path relative to the app root that is passed as --watch. It defaults to
+	// *.dll,*.json,*.config.
+	LiveReloadWatch string `env:"BP_LIVE_RELOAD_WATCH"`
+
+	// BP_LIVE_RELOAD_IGNORE is a comma-separated list of glob patterns that
+	// watchexec should ignore when live reload is enabled. It defaults to
+	// wwwroot/** so that static asset changes do not restart the app.
+	LiveReloadIgnore string `env:"BP_LIVE_RELOAD_IGNORE"`
+
+	// BP_LIVE_RELOAD_WRITABLE_PATHS is a comma-separated list of paths,
+	// relative to the app root, that should be made group read/writable in
+	// addition to the paths watched for live reload.
+	LiveReloadWritablePaths string `env:"BP_LIVE_RELOAD_WRITABLE_PATHS"`
+
 	// BP_LOG_LEVEL determines the amount of logs produced by the buildpack. Set
 	// BP_LOG_LEVEL=DEBUG for more detailed logs.
 	LogLevel string `env:"BP_LOG_LEVEL,default=INFO"`
 
+	// BP_DOTNET_RUN_ARGS holds extra arguments for the app process, such as
+	// --urls or a subcommand. The value is split into arguments using shell
+	// quoting rules at build time.
+	RunArgs string `env:"BP_DOTNET_RUN_ARGS"`
+
+	// BP_DOTNET_ENTRY_ASSEMBLY is the path, relative to the app root, of the
+	// DLL or executable to run. It is only needed when the app root contains
+	// more than one *.runtimeconfig.json or the default choice is not the
+	// right one. A DLL is always run with `dotnet`.
+	EntryAssembly string `env:"BP_DOTNET_ENTRY_ASSEMBLY"`
+
+	// BP_DOTNET_PROCESSES declares additional process types as a
+	// semicolon-separated list of <type>=<command> [args...] entries, where the
+	// command is relative to the app root and a type ending in * marks the
+	// default process. When it is unset, the definitions are read from a
+	// dotnet-processes.toml file in the app root instead.
+	Processes string `env:"BP_DOTNET_PROCESSES"`
+
+	// BP_DOTNET_EF_BUNDLE_PATH is the path, relative to the app root, of the
+	// EF Core migration bundle to expose as the migrate process. By default
+	// the buildpack looks for a file matching *efbundle in the app root.
+	EFBundlePath string `env:"BP_DOTNET_EF_BUNDLE_PATH"`
+
+	// BP_DOTNET_EF_CONNECTION_ENV names the environment variable that the
+	// migrate process reads the database connection string from at launch
+	// when there is no "efcore" service binding. The bundle reads it through
+	// the app's configuration, so it must be the key that the app takes its
+	// connection string from. It defaults to DefaultEFConnectionEnv.
+	EFConnectionEnv string `env:"BP_DOTNET_EF_CONNECTION_ENV"`
+
+	// When BP_DOTNET_OTEL_AUTO_INSTRUMENTATION is true, the buildpack loads
+	// OpenTelemetry .NET automatic instrumentation into the app at launch.
+	OTelAutoInstrumentation bool `env:"BP_DOTNET_OTEL_AUTO_INSTRUMENTATION"`
+
+	// BP_DOTNET_OTEL_AUTO_INSTRUMENTATION_PATH is the path, relative to the app
+	// root, of the unpacked OpenTelemetry .NET automatic instrumentation. When
+	// it is unset, the OTEL_DOTNET_AUTO_HOME set by an earlier buildpack is
+	// used instead.
+	OTelAutoInstrumentationPath string `env:"BP_DOTNET_OTEL_AUTO_INSTRUMENTATION_PATH"`
+
+	// BP_DOTNET_STARTUP_HOOKS is a comma-separated list of startup hook
+	// assemblies that the runtime loads before the app's entry point. Relative
+	// paths are resolved against the app root.
+	StartupHooks string `env:"BP_DOTNET_STARTUP_HOOKS"`
+
+	// When BP_DOTNET_DISABLE_IMAGE_LABELS is true, the buildpack does not
+	// derive org.opencontainers.image.* labels from the app's project or
+	// assembly metadata.
+	DisableImageLabels bool `env:"BP_DOTNET_DISABLE_IMAGE_LABELS"`
+
+	// BP_DOTNET_IMAGE_LABELS adds image labels of its own as shell-quoted
+	// key=value pairs. They override the labels derived from the app.
+	ImageLabels string `env:"BP_DOTNET_IMAGE_LABELS"`
+
+	// When BP_DOTNET_STRICT_NATIVE_LIBRARIES is true, the build fails when a
+	// native library in the app links against a shared library that is
+	// missing from the app and the image's library paths. Otherwise those
+	// libraries are only reported as warnings.
+	StrictNativeLibraries bool `env:"BP_DOTNET_STRICT_NATIVE_LIBRARIES"`
+
+	// BP_DOTNET_REPORT_PATH is a file that the buildpack writes a JSON report
+	// of its detect and build decisions to. A relative path is resolved
+	// against the app root, which puts the report in the app image. The
+	// report, without its launch environment, is always recorded in the
+	// image's io.paketo.dotnet-execute.report label as well.
+	ReportPath string `env:"BP_DOTNET_REPORT_PATH"`
+
 	// When BP_DOTNET_PROJECT_PATH is set to a relative path, the buildpack
 	// will look for project file(s) in that subdirectory to determine which
 	// project to build into the app container.
 	ProjectPath string `env:"BP_DOTNET_PROJECT_PATH"`
+
+	// BP_DOTNET_PROJECT_NAME picks a project by name, without its extension,
+	// when the app root holds several projects or a solution file that lists
+	// several executable projects.
+	ProjectName string `env:"BP_DOTNET_PROJECT_NAME"`
+}
+
+// splitList turns a comma-separated configuration value into its trimmed,
+// non-empty entries.
+func splitList(value string) []string {
+	var list []string
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry != "" {
+			list = append(list, entry)
+		}
+	}
+
+	return list
+}
+
+// runtimeConfigGlob returns the pattern used to find the app's
+// runtimeconfig.json in dir, narrowed to the configured entry assembly when
+// there is one.
+func runtimeConfigGlob(config Configuration, dir string) string {
+	if config.EntryAssembly == "" {
+		return filepath.Join(dir, "*.runtimeconfig.json")
+	}
+
+	return filepath.Join(dir, fmt.Sprintf("%s.runtimeconfig.json", strings.TrimSuffix(config.EntryAssembly, ".dll")))
 }
@@ -15,7 +15,11 @@ import (
 type BuildPlanMetadata struct {
 	Version       string `toml:"version,omitempty"`
 	VersionSource string `toml:"version-source,omitempty"`
+	Build         bool   `toml:"build,omitempty"`
 	Launch        bool   `toml:"launch"`
+
+	// AppKind is only set on the dotnet-app-kind requirement.
+	AppKind AppKind `toml:"app-kind,omitempty"`
 }
 
 //go:generate faux --interface ConfigParser --output fakes/config_parser.go
@@ -25,8 +29,16 @@ type ConfigParser interface {
 
 //go:generate faux --interface ProjectParser --output fakes/project_parser.go
 type ProjectParser interface {
-	FindProjectFile(root string) (string, error)
-	NodeIsRequired(path string) (bool, error)
+	FindProjectFile(root, dir, name string) (ProjectSelection, error)
+	ImageMetadata(root, path string) (ImageMetadata, error)
+	NodeRequirement(root, path string) (NodeRequirement, error)
+	PackageReferences(root, path string) ([]string, error)
+	RuntimeVersion(root, path string) (string, error)
+}
+
+//go:generate faux --interface SDKParser --output fakes/sdk_parser.go
+type SDKParser interface {
+	Parse(root, dir string) (GlobalJSON, error)
 }
 
 // Detect will return a packit.DetectFunc that will be invoked during the
@@ -38,9 +50,14 @@ type ProjectParser interface {
 //
 // # Source Code Apps
 //
-// The buildpack will require .NET Core ASP.NET Runtime at launch-time. It will
-// require ICU at launch time. It will require Nodejs at launch time if the app
-// relies on JavaScript components.
+// The buildpack will require .NET Core ASP.NET Runtime at launch-time, in the
+// version targeted by the project or, failing that, the version that matches
+// the SDK pinned by the nearest global.json. It will require ICU at launch
+// time. It will require Nodejs at build time if the app relies on JavaScript
+// components, along with npm or Yarn when the app's package.json uses them.
+// Nodejs is also required at launch time when the app runs JavaScript on the
+// server. When live reload runs in source mode, it will require the .NET SDK
+// at launch-time instead of a published app and runtime.
 //
 // # Framework-dependent Deployments
 //
@@ -57,11 +74,40 @@ type ProjectParser interface {
 // Self-contained Executables
 // The buildpack will require ICU at launch time. It will require Nodejs at
 // launch time if the app relies on JavaScript components.
+//
+// # App Kind
+//
+// The app is classified as a source app, a framework-dependent deployment or
+// executable, a self-contained app, a single-file bundle or a native AOT
+// executable. The last two are found even without a runtimeconfig.json next
+// to them. An app with a project file is a source app in Build too, although
+// Build runs the output that was published from it.
+//
+// The buildpack provides "dotnet-app-kind", so that later buildpacks can
+// require it to only pass detection for .NET apps, and requires it with the
+// kind in its metadata for Build to read. Later buildpacks only learn the
+// kind from BPI_DOTNET_APP_KIND, which Build sets.
+//
+// # Native Libraries
+//
+// When the app's deps.json or package references show that it loads system
+// libraries such as libgdiplus, libfontconfig, libgssapi_krb5 or tzdata, the
+// buildpack will optionally require them at launch time. The build plan
+// falls back to one without them when no buildpack provides them.
+//
+// # Report
+//
+// When BP_DOTNET_REPORT_PATH is set, the app kind, the files that were chosen
+// and each requirement, with the reasons for it, are written to that file as
+// JSON for Build to complete. A relative path is resolved against the app
+// root. Detection runs once for each order group that holds the buildpack,
+// and each run writes the same report, replacing the one before.
 func Detect(
 	config Configuration,
 	logger scribe.Emitter,
 	configParser ConfigParser,
 	projectParser ProjectParser,
+	sdkParser SDKParser,
 ) packit.DetectFunc {
 	return func(context packit.DetectContext) (packit.DetectResult, error) {
 		logger.Debug.Process("Build configuration:")
@@ -78,24 +124,38 @@ func Detect(
 		}
 		logger.Debug.Break()
 
+		switch config.LiveReloadMode {
+		case "", LiveReloadModeBinary, LiveReloadModeSource:
+		default:
+			return packit.DetectResult{}, fmt.Errorf("unsupported BP_LIVE_RELOAD_MODE %q: must be %q or %q", config.LiveReloadMode, LiveReloadModeBinary, LiveReloadModeSource)
+		}
+
+		sourceReload := config.LiveReloadEnabled && config.LiveReloadMode == LiveReloadModeSource
+
 		requirements := []packit.BuildPlanRequirement{}
+		report := Report{Files: map[string]string{}}
+
+		require := func(requirement packit.BuildPlanRequirement, reasons ...string) {
+			requirements = append(requirements, requirement)
+			report.Requirements = append(report.Requirements, newReportRequirement(requirement, false, reasons))
+		}
 
-		if config.LiveReloadEnabled {
-			requirements = append(requirements, packit.BuildPlanRequirement{
+		if config.LiveReloadEnabled && !sourceReload {
+			require(packit.BuildPlanRequirement{
 				Name: "watchexec",
 				Metadata: BuildPlanMetadata{
 					Launch: true,
 				},
-			})
+			}, "BP_LIVE_RELOAD_ENABLED=true")
 		}
 
 		if config.DebugEnabled {
-			requirements = append(requirements, packit.BuildPlanRequirement{
+			require(packit.BuildPlanRequirement{
 				Name: "vsdbg",
 				Metadata: BuildPlanMetadata{
 					Launch: true,
 				},
-			})
+			}, "BP_DEBUG_ENABLED=true")
 		}
 
 		root := context.WorkingDir
@@ -105,73 +165,208 @@ func Detect(
 
 		logger.Debug.Process("Looking for .NET project files in '%s'", root)
 
-		runtimeConfig, err := configParser.Parse(filepath.Join(root, "*.runtimeconfig.json"))
+		runtimeConfig, err := configParser.Parse(runtimeConfigGlob(config, root))
 		if err != nil && !errors.Is(err, os.ErrNotExist) {
 			return packit.DetectResult{}, err
 		}
+
+		// Single-file bundles and native AOT executables are published
+		// without a runtimeconfig.json next to them.
+		detected := runtimeConfig.Path
+		runtimeConfigSource := fmt.Sprintf("%s.runtimeconfig.json", runtimeConfig.AppName)
+		if runtimeConfig.Path != "" {
+			report.Files["runtime_config"] = runtimeConfig.Path
+		} else {
+			runtimeConfig, _, err = FindExecutableApp(root, config.EntryAssembly)
+			if err != nil {
+				return packit.DetectResult{}, err
+			}
+
+			if runtimeConfig.AppName != "" {
+				detected = filepath.Join(root, runtimeConfig.AppName)
+				runtimeConfigSource = fmt.Sprintf("the runtimeconfig.json bundled in %s", runtimeConfig.AppName)
+				report.Files["executable"] = detected
+			}
+		}
+
 		// FDE + FDD cases
 		if runtimeConfig.RuntimeVersion != "" {
-			logger.Debug.Subprocess("Detected '%s'", filepath.Join(root, fmt.Sprintf("%s.runtimeconfig.json", runtimeConfig.AppName)))
+			logger.Debug.Subprocess("Detected '%s'", detected)
 			logger.Debug.Break()
 
-			requirements = append(requirements, packit.BuildPlanRequirement{
+			require(packit.BuildPlanRequirement{
 				Name: "dotnet-core-aspnet-runtime",
 				Metadata: BuildPlanMetadata{
 					Launch: true,
 				},
-			})
+			}, fmt.Sprintf("%s needs runtime %s", runtimeConfigSource, runtimeConfig.RuntimeVersion))
 		}
 
-		projectFile, err := projectParser.FindProjectFile(root)
+		project, err := projectParser.FindProjectFile(context.WorkingDir, root, config.ProjectName)
 		if err != nil {
 			return packit.DetectResult{}, err
 		}
+		projectFile := project.Path
 
-		if runtimeConfig.Path == "" && projectFile == "" {
-			return packit.DetectResult{}, packit.Fail.WithMessage("no *.runtimeconfig.json or project file found")
+		kind, err := ClassifyApp(runtimeConfig, root, projectFile)
+		if err != nil {
+			return packit.DetectResult{}, err
+		}
+
+		if kind == "" {
+			return packit.DetectResult{}, packit.Fail.WithMessage("no *.runtimeconfig.json, app executable or project file found")
+		}
+
+		logger.Debug.Subprocess("App kind is %s", kind)
+		logger.Debug.Break()
+		report.AppKind = kind
+
+		if sourceReload && projectFile == "" {
+			return packit.DetectResult{}, packit.Fail.WithMessage("BP_LIVE_RELOAD_MODE=%s requires a project file", LiveReloadModeSource)
 		}
 
 		if projectFile != "" {
 			logger.Debug.Subprocess("Detected '%s'", projectFile)
+			for _, reason := range project.Reasons {
+				logger.Debug.Action(reason)
+			}
 			logger.Debug.Break()
 
-			requirements = append(requirements, packit.BuildPlanRequirement{
-				Name: "dotnet-application",
-				Metadata: BuildPlanMetadata{
-					Launch: true,
-				},
-			})
+			report.Files["project_file"] = projectFile
 
-			requirements = append(requirements, packit.BuildPlanRequirement{
-				Name: "dotnet-core-aspnet-runtime",
-				Metadata: BuildPlanMetadata{
+			if sourceReload {
+				require(packit.BuildPlanRequirement{
+					Name: "dotnet-sdk",
+					Metadata: BuildPlanMetadata{
+						Launch: true,
+					},
+				}, fmt.Sprintf("BP_LIVE_RELOAD_MODE=%s runs %s with dotnet watch", LiveReloadModeSource, filepath.Base(projectFile)))
+			} else {
+				require(packit.BuildPlanRequirement{
+					Name: "dotnet-application",
+					Metadata: BuildPlanMetadata{
+						Launch: true,
+					},
+				}, fmt.Sprintf("%s is built from source", filepath.Base(projectFile)))
+
+				runtime := BuildPlanMetadata{
 					Launch: true,
-				},
-			})
+				}
+				runtimeReason := fmt.Sprintf("%s is built from source", filepath.Base(projectFile))
+
+				// The project's target framework decides which runtime the app
+				// needs. The SDK pinned in global.json is only a fallback, as
+				// newer SDKs can build for older runtimes.
+				version, err := projectParser.RuntimeVersion(context.WorkingDir, projectFile)
+				if err != nil {
+					return packit.DetectResult{}, err
+				}
+
+				if version != "" {
+					logger.Debug.Subprocess("Project targets runtime '%s'", version)
+					logger.Debug.Break()
 
-			nodeIsRequired, err := projectParser.NodeIsRequired(projectFile)
+					runtime.Version = version
+					runtime.VersionSource = filepath.Base(projectFile)
+					runtimeReason = fmt.Sprintf("%s targets runtime %s", filepath.Base(projectFile), version)
+				} else {
+					globalJSON, err := sdkParser.Parse(context.WorkingDir, filepath.Dir(projectFile))
+					if err != nil {
+						return packit.DetectResult{}, err
+					}
+
+					constraint, err := globalJSON.RuntimeConstraint()
+					if err != nil {
+						return packit.DetectResult{}, err
+					}
+
+					if constraint != "" {
+						logger.Debug.Subprocess("Found SDK %s pinned in '%s', requiring runtime '%s'", globalJSON.SDKVersion, globalJSON.Path, constraint)
+						logger.Debug.Break()
+
+						runtime.Version = constraint
+						runtime.VersionSource = "global.json"
+						runtimeReason = fmt.Sprintf("%s pins SDK %s", globalJSON.Path, globalJSON.SDKVersion)
+						report.Files["global_json"] = globalJSON.Path
+					}
+				}
+
+				require(packit.BuildPlanRequirement{
+					Name:     "dotnet-core-aspnet-runtime",
+					Metadata: runtime,
+				}, runtimeReason)
+			}
+
+			node, err := projectParser.NodeRequirement(context.WorkingDir, projectFile)
 			if err != nil {
 				return packit.DetectResult{}, err
 			}
 
-			if nodeIsRequired {
-				requirements = append(requirements, packit.BuildPlanRequirement{
+			if node.Required {
+				// Apps built by dotnet watch at launch need their build tools
+				// in the running container too.
+				launch := node.Launch || sourceReload
+
+				reasons := node.Reasons
+				if sourceReload {
+					reasons = append(reasons, fmt.Sprintf("BP_LIVE_RELOAD_MODE=%s builds the app at launch", LiveReloadModeSource))
+				}
+
+				require(packit.BuildPlanRequirement{
 					Name: "node",
 					Metadata: BuildPlanMetadata{
-						VersionSource: filepath.Base(projectFile),
-						Launch:        true,
+						Version:       node.Version,
+						VersionSource: node.VersionSource,
+						Build:         true,
+						Launch:        launch,
 					},
-				})
+				}, reasons...)
+
+				// pnpm is installed by Node.js itself through corepack.
+				if node.PackageManager == "npm" || node.PackageManager == "yarn" {
+					require(packit.BuildPlanRequirement{
+						Name: node.PackageManager,
+						Metadata: BuildPlanMetadata{
+							Build:  true,
+							Launch: launch,
+						},
+					}, fmt.Sprintf("the project's JavaScript components use %s", node.PackageManager))
+				}
 			}
 		}
 
 		// ICU will always be append onto the build plan requirements
-		requirements = append(requirements, packit.BuildPlanRequirement{
+		require(packit.BuildPlanRequirement{
 			Name: "icu",
 			Metadata: BuildPlanMetadata{
 				Launch: true,
 			},
-		})
+		}, ".NET uses ICU for globalization")
+
+		require(packit.BuildPlanRequirement{
+			Name: AppKindPlanEntry,
+			Metadata: BuildPlanMetadata{
+				AppKind: kind,
+			},
+		}, fmt.Sprintf("the app kind is %s", kind))
+
+		var packageReferences []string
+		if projectFile != "" {
+			packageReferences, err = projectParser.PackageReferences(context.WorkingDir, projectFile)
+			if err != nil {
+				return packit.DetectResult{}, err
+			}
+		}
+
+		var depsPath string
+		if runtimeConfig.Path != "" {
+			depsPath = strings.TrimSuffix(runtimeConfig.Path, ".runtimeconfig.json") + ".deps.json"
+		}
+
+		libraries, err := FindNativeLibraries(depsPath, packageReferences)
+		if err != nil {
+			return packit.DetectResult{}, err
+		}
 
 		logger.Debug.Process("Returning build plan")
 		logger.Debug.Subprocess("Requirements:")
@@ -180,10 +375,59 @@ func Detect(
 		}
 		logger.Debug.Break()
 
+		provides := []packit.BuildPlanProvision{
+			{Name: AppKindPlanEntry},
+		}
+
+		plan := packit.BuildPlan{
+			Provides: provides,
+			Requires: requirements,
+		}
+
+		if len(libraries) > 0 {
+			// Native libraries are optional: the first plan asks a supporting
+			// buildpack to provide them, and the alternative lets the build go
+			// ahead without them. Build can not tell which plan was chosen, so
+			// it lists the libraries either way.
+			withLibraries := append([]packit.BuildPlanRequirement{}, requirements...)
+			logger.Debug.Subprocess("Optional requirements:")
+			for _, library := range libraries {
+				logger.Debug.Action("%s (%s)", library.Name, strings.Join(library.Sources, ", "))
+
+				requirement := packit.BuildPlanRequirement{
+					Name: library.Name,
+					Metadata: BuildPlanMetadata{
+						Launch: true,
+					},
+				}
+				withLibraries = append(withLibraries, requirement)
+				report.Requirements = append(report.Requirements, newReportRequirement(requirement, true, []string{
+					fmt.Sprintf("needed by %s", strings.Join(library.Sources, ", ")),
+				}))
+			}
+			logger.Debug.Break()
+
+			plan = packit.BuildPlan{
+				Provides: provides,
+				Requires: withLibraries,
+				Or: []packit.BuildPlan{
+					{Provides: provides, Requires: requirements},
+				},
+			}
+		}
+
+		// Detection runs in every order group that holds this buildpack. The
+		// report only depends on the app and the configuration, so each run
+		// writes the same report over the last one.
+		if path := reportPath(config, context.WorkingDir); path != "" {
+			err = report.Write(path)
+			if err != nil {
+				return packit.DetectResult{}, err
+			}
+		}
+
 		return packit.DetectResult{
-			Plan: packit.BuildPlan{
-				Requires: requirements,
-			},
+			Plan: plan,
 		}, nil
 	}
 }
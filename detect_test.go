@@ -26,6 +26,7 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 		logger              scribe.Emitter
 		projectParser       *fakes.ProjectParser
 		runtimeConfigParser *fakes.ConfigParser
+		sdkParser           *fakes.SDKParser
 
 		detect packit.DetectFunc
 	)
@@ -40,11 +41,12 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 			Path: filepath.Join(workingDir, "some-app.runtimeconfig.json"),
 		}
 		projectParser = &fakes.ProjectParser{}
+		sdkParser = &fakes.SDKParser{}
 
 		buffer = bytes.NewBuffer(nil)
 		logger = scribe.NewEmitter(buffer)
 
-		detect = dotnetexecute.Detect(dotnetexecute.Configuration{}, logger, runtimeConfigParser, projectParser)
+		detect = dotnetexecute.Detect(dotnetexecute.Configuration{}, logger, runtimeConfigParser, projectParser, sdkParser)
 	})
 
 	it.After(func() {
@@ -65,6 +67,7 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 			})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result.Plan).To(Equal(packit.BuildPlan{
+				Provides: []packit.BuildPlanProvision{{Name: "dotnet-app-kind"}},
 				Requires: []packit.BuildPlanRequirement{
 					{
 						Name: "icu",
@@ -72,6 +75,12 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 							Launch: true,
 						},
 					},
+					{
+						Name: "dotnet-app-kind",
+						Metadata: dotnetexecute.BuildPlanMetadata{
+							AppKind: "self-contained",
+						},
+					},
 				},
 			}))
 
@@ -94,6 +103,7 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 				})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(result.Plan).To(Equal(packit.BuildPlan{
+					Provides: []packit.BuildPlanProvision{{Name: "dotnet-app-kind"}},
 					Requires: []packit.BuildPlanRequirement{
 						{
 							Name: "dotnet-core-aspnet-runtime",
@@ -107,6 +117,12 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 								Launch: true,
 							},
 						},
+						{
+							Name: "dotnet-app-kind",
+							Metadata: dotnetexecute.BuildPlanMetadata{
+								AppKind: "framework-dependent-executable",
+							},
+						},
 					},
 				}))
 
@@ -130,6 +146,7 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 				})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(result.Plan).To(Equal(packit.BuildPlan{
+					Provides: []packit.BuildPlanProvision{{Name: "dotnet-app-kind"}},
 					Requires: []packit.BuildPlanRequirement{
 						{
 							Name: "dotnet-core-aspnet-runtime",
@@ -143,6 +160,12 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 								Launch: true,
 							},
 						},
+						{
+							Name: "dotnet-app-kind",
+							Metadata: dotnetexecute.BuildPlanMetadata{
+								AppKind: "framework-dependent-deployment",
+							},
+						},
 					},
 				}))
 
@@ -167,6 +190,7 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 				})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(result.Plan).To(Equal(packit.BuildPlan{
+					Provides: []packit.BuildPlanProvision{{Name: "dotnet-app-kind"}},
 					Requires: []packit.BuildPlanRequirement{
 						{
 							Name: "dotnet-core-aspnet-runtime",
@@ -180,6 +204,12 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 								Launch: true,
 							},
 						},
+						{
+							Name: "dotnet-app-kind",
+							Metadata: dotnetexecute.BuildPlanMetadata{
+								AppKind: "framework-dependent-executable",
+							},
+						},
 					},
 				}))
 
@@ -189,9 +219,55 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 
+	context("there is a single-file bundle and no *.runtimeconfig.json", func() {
+		it.Before(func() {
+			runtimeConfigParser.ParseCall.Returns.RuntimeConfig = dotnetexecute.RuntimeConfig{}
+			runtimeConfigParser.ParseCall.Returns.Error = os.ErrNotExist
+
+			Expect(writeSingleFileBundle(filepath.Join(workingDir, "some-app"), 6, 0,
+				bundleFile{Type: 1, Name: "some-app.dll"},
+				bundleFile{Type: 4, Name: "some-app.runtimeconfig.json", Content: `{
+					"runtimeOptions": {
+						"framework": {"name": "Microsoft.NETCore.App", "version": "8.0.0"}
+					}
+				}`},
+			)).To(Succeed())
+		})
+
+		it("requires the runtime from the bundled runtimeconfig.json", func() {
+			result, err := detect(packit.DetectContext{
+				WorkingDir: workingDir,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plan).To(Equal(packit.BuildPlan{
+				Provides: []packit.BuildPlanProvision{{Name: "dotnet-app-kind"}},
+				Requires: []packit.BuildPlanRequirement{
+					{
+						Name: "dotnet-core-aspnet-runtime",
+						Metadata: dotnetexecute.BuildPlanMetadata{
+							Launch: true,
+						},
+					},
+					{
+						Name: "icu",
+						Metadata: dotnetexecute.BuildPlanMetadata{
+							Launch: true,
+						},
+					},
+					{
+						Name: "dotnet-app-kind",
+						Metadata: dotnetexecute.BuildPlanMetadata{
+							AppKind: "single-file",
+						},
+					},
+				},
+			}))
+		})
+	})
+
 	context("there is a proj file present (and no .runtimeconfig.json)", func() {
 		it.Before(func() {
-			projectParser.FindProjectFileCall.Returns.String = "/path/to/some-file.csproj"
+			projectParser.FindProjectFileCall.Returns.ProjectSelection = dotnetexecute.ProjectSelection{Path: "/path/to/some-file.csproj"}
 		})
 
 		it("detects successfully", func() {
@@ -200,6 +276,7 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 			})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result.Plan).To(Equal(packit.BuildPlan{
+				Provides: []packit.BuildPlanProvision{{Name: "dotnet-app-kind"}},
 				Requires: []packit.BuildPlanRequirement{
 					{
 						Name: "dotnet-application",
@@ -219,19 +296,25 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 							Launch: true,
 						},
 					},
+					{
+						Name: "dotnet-app-kind",
+						Metadata: dotnetexecute.BuildPlanMetadata{
+							AppKind: "source",
+						},
+					},
 				},
 			}))
 
 			Expect(runtimeConfigParser.ParseCall.Receives.Glob).To(Equal(filepath.Join(workingDir, "*.runtimeconfig.json")))
 
 			Expect(projectParser.FindProjectFileCall.Receives.Root).To(Equal(workingDir))
-			Expect(projectParser.NodeIsRequiredCall.Receives.Path).To(Equal("/path/to/some-file.csproj"))
+			Expect(projectParser.NodeRequirementCall.Receives.Path).To(Equal("/path/to/some-file.csproj"))
 		})
 	})
 
 	context("the proj file specifies a version of dotnet-runtime", func() {
 		it.Before(func() {
-			projectParser.FindProjectFileCall.Returns.String = "/path/to/some-file.csproj"
+			projectParser.FindProjectFileCall.Returns.ProjectSelection = dotnetexecute.ProjectSelection{Path: "/path/to/some-file.csproj"}
 		})
 
 		it("requires that version for dotnet-core-aspnet-runtime, requires a 70.* version of ICU, and detects successfully", func() {
@@ -240,6 +323,7 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 			})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result.Plan).To(Equal(packit.BuildPlan{
+				Provides: []packit.BuildPlanProvision{{Name: "dotnet-app-kind"}},
 				Requires: []packit.BuildPlanRequirement{
 					{
 						Name: "dotnet-application",
@@ -259,19 +343,82 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 							Launch: true,
 						},
 					},
+					{
+						Name: "dotnet-app-kind",
+						Metadata: dotnetexecute.BuildPlanMetadata{
+							AppKind: "source",
+						},
+					},
 				},
 			}))
 
 			Expect(runtimeConfigParser.ParseCall.Receives.Glob).To(Equal(filepath.Join(workingDir, "*.runtimeconfig.json")))
 
 			Expect(projectParser.FindProjectFileCall.Receives.Root).To(Equal(workingDir))
-			Expect(projectParser.NodeIsRequiredCall.Receives.Path).To(Equal("/path/to/some-file.csproj"))
+			Expect(projectParser.NodeRequirementCall.Receives.Path).To(Equal("/path/to/some-file.csproj"))
+		})
+	})
+
+	context("the proj file targets a runtime version", func() {
+		it.Before(func() {
+			projectParser.FindProjectFileCall.Returns.ProjectSelection = dotnetexecute.ProjectSelection{Path: "/path/to/some-file.csproj"}
+			projectParser.RuntimeVersionCall.Returns.String = "8.0.*"
+			sdkParser.ParseCall.Returns.GlobalJSON = dotnetexecute.GlobalJSON{
+				Path:       "/path/global.json",
+				SDKVersion: "9.0.100",
+			}
+		})
+
+		it("requires that version of dotnet-core-aspnet-runtime over the global.json SDK", func() {
+			result, err := detect(packit.DetectContext{
+				WorkingDir: workingDir,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plan.Requires).To(ContainElement(packit.BuildPlanRequirement{
+				Name: "dotnet-core-aspnet-runtime",
+				Metadata: dotnetexecute.BuildPlanMetadata{
+					Version:       "8.0.*",
+					VersionSource: "some-file.csproj",
+					Launch:        true,
+				},
+			}))
+
+			Expect(projectParser.RuntimeVersionCall.Receives.Path).To(Equal("/path/to/some-file.csproj"))
+			Expect(sdkParser.ParseCall.CallCount).To(Equal(0))
+		})
+	})
+
+	context("a global.json pins the SDK", func() {
+		it.Before(func() {
+			projectParser.FindProjectFileCall.Returns.ProjectSelection = dotnetexecute.ProjectSelection{Path: "/path/to/some-file.csproj"}
+			sdkParser.ParseCall.Returns.GlobalJSON = dotnetexecute.GlobalJSON{
+				Path:       "/path/global.json",
+				SDKVersion: "8.0.204",
+			}
+		})
+
+		it("requires the matching dotnet-core-aspnet-runtime version", func() {
+			result, err := detect(packit.DetectContext{
+				WorkingDir: workingDir,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plan.Requires).To(ContainElement(packit.BuildPlanRequirement{
+				Name: "dotnet-core-aspnet-runtime",
+				Metadata: dotnetexecute.BuildPlanMetadata{
+					Version:       "8.0.*",
+					VersionSource: "global.json",
+					Launch:        true,
+				},
+			}))
+
+			Expect(sdkParser.ParseCall.Receives.Root).To(Equal(workingDir))
+			Expect(sdkParser.ParseCall.Receives.Dir).To(Equal("/path/to"))
 		})
 	})
 
 	context("the proj file requires ASPNet", func() {
 		it.Before(func() {
-			projectParser.FindProjectFileCall.Returns.String = "/path/to/some-file.csproj"
+			projectParser.FindProjectFileCall.Returns.ProjectSelection = dotnetexecute.ProjectSelection{Path: "/path/to/some-file.csproj"}
 		})
 
 		it("requires that version for dotnet-core-aspnet-runtime correctly", func() {
@@ -280,6 +427,7 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 			})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result.Plan).To(Equal(packit.BuildPlan{
+				Provides: []packit.BuildPlanProvision{{Name: "dotnet-app-kind"}},
 				Requires: []packit.BuildPlanRequirement{
 					{
 						Name: "dotnet-application",
@@ -299,28 +447,40 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 							Launch: true,
 						},
 					},
+					{
+						Name: "dotnet-app-kind",
+						Metadata: dotnetexecute.BuildPlanMetadata{
+							AppKind: "source",
+						},
+					},
 				},
 			}))
 
 			Expect(runtimeConfigParser.ParseCall.Receives.Glob).To(Equal(filepath.Join(workingDir, "*.runtimeconfig.json")))
 
 			Expect(projectParser.FindProjectFileCall.Receives.Root).To(Equal(workingDir))
-			Expect(projectParser.NodeIsRequiredCall.Receives.Path).To(Equal("/path/to/some-file.csproj"))
+			Expect(projectParser.NodeRequirementCall.Receives.Path).To(Equal("/path/to/some-file.csproj"))
 		})
 	})
 
 	context("the proj file requires Node", func() {
 		it.Before(func() {
-			projectParser.FindProjectFileCall.Returns.String = "/path/to/some-file.csproj"
-			projectParser.NodeIsRequiredCall.Returns.Bool = true
+			projectParser.FindProjectFileCall.Returns.ProjectSelection = dotnetexecute.ProjectSelection{Path: "/path/to/some-file.csproj"}
+			projectParser.NodeRequirementCall.Returns.NodeRequirement = dotnetexecute.NodeRequirement{
+				Required:       true,
+				PackageManager: "yarn",
+				Version:        "20.*",
+				VersionSource:  "package.json",
+			}
 		})
 
-		it("requires that version for dotnet-core-aspnet-runtime and node", func() {
+		it("requires dotnet-core-aspnet-runtime, and node and the package manager at build time", func() {
 			result, err := detect(packit.DetectContext{
 				WorkingDir: workingDir,
 			})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result.Plan).To(Equal(packit.BuildPlan{
+				Provides: []packit.BuildPlanProvision{{Name: "dotnet-app-kind"}},
 				Requires: []packit.BuildPlanRequirement{
 					{
 						Name: "dotnet-application",
@@ -337,8 +497,15 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 					{
 						Name: "node",
 						Metadata: dotnetexecute.BuildPlanMetadata{
-							VersionSource: "some-file.csproj",
-							Launch:        true,
+							Version:       "20.*",
+							VersionSource: "package.json",
+							Build:         true,
+						},
+					},
+					{
+						Name: "yarn",
+						Metadata: dotnetexecute.BuildPlanMetadata{
+							Build: true,
 						},
 					},
 					{
@@ -347,13 +514,236 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 							Launch: true,
 						},
 					},
+					{
+						Name: "dotnet-app-kind",
+						Metadata: dotnetexecute.BuildPlanMetadata{
+							AppKind: "source",
+						},
+					},
 				},
 			}))
 
 			Expect(runtimeConfigParser.ParseCall.Receives.Glob).To(Equal(filepath.Join(workingDir, "*.runtimeconfig.json")))
 
 			Expect(projectParser.FindProjectFileCall.Receives.Root).To(Equal(workingDir))
-			Expect(projectParser.NodeIsRequiredCall.Receives.Path).To(Equal("/path/to/some-file.csproj"))
+			Expect(projectParser.NodeRequirementCall.Receives.Path).To(Equal("/path/to/some-file.csproj"))
+		})
+	})
+
+	context("the app runs Node at launch", func() {
+		it.Before(func() {
+			projectParser.FindProjectFileCall.Returns.ProjectSelection = dotnetexecute.ProjectSelection{Path: "/path/to/some-file.csproj"}
+			projectParser.NodeRequirementCall.Returns.NodeRequirement = dotnetexecute.NodeRequirement{
+				Required:       true,
+				Launch:         true,
+				PackageManager: "npm",
+			}
+		})
+
+		it("requires node and the package manager at build and launch time", func() {
+			result, err := detect(packit.DetectContext{
+				WorkingDir: workingDir,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plan.Requires).To(ContainElements(
+				packit.BuildPlanRequirement{
+					Name: "node",
+					Metadata: dotnetexecute.BuildPlanMetadata{
+						Build:  true,
+						Launch: true,
+					},
+				},
+				packit.BuildPlanRequirement{
+					Name: "npm",
+					Metadata: dotnetexecute.BuildPlanMetadata{
+						Build:  true,
+						Launch: true,
+					},
+				},
+			))
+		})
+	})
+
+	context("the app needs native libraries", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "some-app.deps.json"), []byte(`{
+				"libraries": {
+					"SkiaSharp/2.88.7": { "type": "package" }
+				}
+			}`), 0600)).To(Succeed())
+
+			projectParser.FindProjectFileCall.Returns.ProjectSelection = dotnetexecute.ProjectSelection{Path: "/path/to/some-file.csproj"}
+			projectParser.PackageReferencesCall.Returns.StringSlice = []string{"Npgsql"}
+		})
+
+		it("optionally requires them", func() {
+			result, err := detect(packit.DetectContext{
+				WorkingDir: workingDir,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			requirements := []packit.BuildPlanRequirement{
+				{
+					Name: "dotnet-application",
+					Metadata: dotnetexecute.BuildPlanMetadata{
+						Launch: true,
+					},
+				},
+				{
+					Name: "dotnet-core-aspnet-runtime",
+					Metadata: dotnetexecute.BuildPlanMetadata{
+						Launch: true,
+					},
+				},
+				{
+					Name: "icu",
+					Metadata: dotnetexecute.BuildPlanMetadata{
+						Launch: true,
+					},
+				},
+				{
+					Name: "dotnet-app-kind",
+					Metadata: dotnetexecute.BuildPlanMetadata{
+						AppKind: "source",
+					},
+				},
+			}
+			provides := []packit.BuildPlanProvision{{Name: "dotnet-app-kind"}}
+
+			Expect(result.Plan).To(Equal(packit.BuildPlan{
+				Provides: provides,
+				Requires: append(append([]packit.BuildPlanRequirement{}, requirements...),
+					packit.BuildPlanRequirement{
+						Name: "libgssapi_krb5",
+						Metadata: dotnetexecute.BuildPlanMetadata{
+							Launch: true,
+						},
+					},
+					packit.BuildPlanRequirement{
+						Name: "libfontconfig",
+						Metadata: dotnetexecute.BuildPlanMetadata{
+							Launch: true,
+						},
+					},
+				),
+				Or: []packit.BuildPlan{
+					{Provides: provides, Requires: requirements},
+				},
+			}))
+
+			Expect(projectParser.PackageReferencesCall.Receives.Path).To(Equal("/path/to/some-file.csproj"))
+		})
+	})
+
+	context("when BP_DOTNET_REPORT_PATH is set", func() {
+		var reportPath string
+
+		it.Before(func() {
+			reportPath = filepath.Join(workingDir, "reports", "dotnet-execute.json")
+
+			Expect(os.WriteFile(filepath.Join(workingDir, "some-app.deps.json"), []byte(`{
+				"libraries": {
+					"SkiaSharp/2.88.7": { "type": "package" }
+				}
+			}`), 0600)).To(Succeed())
+
+			runtimeConfigParser.ParseCall.Returns.RuntimeConfig = dotnetexecute.RuntimeConfig{
+				Path:           filepath.Join(workingDir, "some-app.runtimeconfig.json"),
+				AppName:        "some-app",
+				RuntimeVersion: "8.0.0",
+				Executable:     true,
+			}
+			projectParser.FindProjectFileCall.Returns.ProjectSelection = dotnetexecute.ProjectSelection{Path: "/path/to/some-file.csproj"}
+			projectParser.RuntimeVersionCall.Returns.String = "8.0.*"
+			projectParser.NodeRequirementCall.Returns.NodeRequirement = dotnetexecute.NodeRequirement{
+				Required:       true,
+				PackageManager: "npm",
+				Reasons:        []string{"found ClientApp/package.json"},
+			}
+
+			detect = dotnetexecute.Detect(dotnetexecute.Configuration{
+				ReportPath: reportPath,
+			}, logger, runtimeConfigParser, projectParser, sdkParser)
+		})
+
+		it("writes a report of the requirements and the reasons for them", func() {
+			_, err := detect(packit.DetectContext{
+				WorkingDir: workingDir,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			report, err := dotnetexecute.ReadReport(reportPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report).To(Equal(dotnetexecute.Report{
+				AppKind: "source",
+				Files: map[string]string{
+					"runtime_config": filepath.Join(workingDir, "some-app.runtimeconfig.json"),
+					"project_file":   "/path/to/some-file.csproj",
+				},
+				Requirements: []dotnetexecute.ReportRequirement{
+					{Name: "dotnet-core-aspnet-runtime", Launch: true, Reasons: []string{"some-app.runtimeconfig.json needs runtime 8.0.0"}},
+					{Name: "dotnet-application", Launch: true, Reasons: []string{"some-file.csproj is built from source"}},
+					{Name: "dotnet-core-aspnet-runtime", Version: "8.0.*", VersionSource: "some-file.csproj", Launch: true, Reasons: []string{"some-file.csproj targets runtime 8.0.*"}},
+					{Name: "node", Build: true, Reasons: []string{"found ClientApp/package.json"}},
+					{Name: "npm", Build: true, Reasons: []string{"the project's JavaScript components use npm"}},
+					{Name: "icu", Launch: true, Reasons: []string{".NET uses ICU for globalization"}},
+					{Name: "dotnet-app-kind", Reasons: []string{"the app kind is source"}},
+					{Name: "libfontconfig", Launch: true, Optional: true, Reasons: []string{"needed by package SkiaSharp"}},
+				},
+			}))
+		})
+
+		context("when detection runs for more than one order group", func() {
+			it("writes the same report over the one before", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				first, err := os.ReadFile(reportPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				second, err := os.ReadFile(reportPath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(second).To(Equal(first))
+			})
+		})
+
+		context("when BP_DOTNET_REPORT_PATH is relative", func() {
+			it.Before(func() {
+				detect = dotnetexecute.Detect(dotnetexecute.Configuration{
+					ReportPath: filepath.Join("reports", "dotnet-execute.json"),
+				}, logger, runtimeConfigParser, projectParser, sdkParser)
+			})
+
+			it("writes the report in the app root", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				report, err := dotnetexecute.ReadReport(reportPath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(report.AppKind).To(Equal(dotnetexecute.AppKindSource))
+			})
+		})
+
+		context("when the report can not be written", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "reports"), nil, 0600)).To(Succeed())
+			})
+
+			it("fails", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).To(MatchError(ContainSubstring("failed to write report")))
+			})
 		})
 	})
 
@@ -361,12 +751,12 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 		it.Before(func() {
 			detect = dotnetexecute.Detect(dotnetexecute.Configuration{
 				ProjectPath: "src/proj1",
-			}, logger, runtimeConfigParser, projectParser)
+			}, logger, runtimeConfigParser, projectParser, sdkParser)
 		})
 
 		context("project-path directory contains a proj file", func() {
 			it.Before(func() {
-				projectParser.FindProjectFileCall.Returns.String = "/path/to/some-file.csproj"
+				projectParser.FindProjectFileCall.Returns.ProjectSelection = dotnetexecute.ProjectSelection{Path: "/path/to/some-file.csproj"}
 			})
 
 			it("detects successfully", func() {
@@ -377,17 +767,66 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 
 				Expect(runtimeConfigParser.ParseCall.Receives.Glob).To(Equal(filepath.Join(workingDir, "src/proj1", "*.runtimeconfig.json")))
 
-				Expect(projectParser.FindProjectFileCall.Receives.Root).To(Equal(filepath.Join(workingDir, "src/proj1")))
-				Expect(projectParser.NodeIsRequiredCall.Receives.Path).To(Equal("/path/to/some-file.csproj"))
+				Expect(projectParser.FindProjectFileCall.Receives.Root).To(Equal(workingDir))
+				Expect(projectParser.FindProjectFileCall.Receives.Dir).To(Equal(filepath.Join(workingDir, "src/proj1")))
+				Expect(projectParser.NodeRequirementCall.Receives.Root).To(Equal(workingDir))
+				Expect(projectParser.NodeRequirementCall.Receives.Path).To(Equal("/path/to/some-file.csproj"))
 			})
 		})
 	})
 
+	context("when BP_DOTNET_PROJECT_NAME is set", func() {
+		it.Before(func() {
+			detect = dotnetexecute.Detect(dotnetexecute.Configuration{
+				ProjectName: "Api",
+			}, logger, runtimeConfigParser, projectParser, sdkParser)
+
+			projectParser.FindProjectFileCall.Returns.ProjectSelection = dotnetexecute.ProjectSelection{
+				Path:    "/path/to/src/Api/Api.csproj",
+				Reasons: []string{"src/Api/Api.csproj matches BP_DOTNET_PROJECT_NAME=Api"},
+			}
+		})
+
+		it("looks for the project with that name and logs why it was chosen", func() {
+			logger = scribe.NewEmitter(buffer).WithLevel("DEBUG")
+			detect = dotnetexecute.Detect(dotnetexecute.Configuration{
+				ProjectName: "Api",
+			}, logger, runtimeConfigParser, projectParser, sdkParser)
+
+			_, err := detect(packit.DetectContext{
+				WorkingDir: workingDir,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("src/Api/Api.csproj matches BP_DOTNET_PROJECT_NAME=Api"))
+
+			Expect(projectParser.FindProjectFileCall.Receives.Root).To(Equal(workingDir))
+			Expect(projectParser.FindProjectFileCall.Receives.Name).To(Equal("Api"))
+			Expect(projectParser.NodeRequirementCall.Receives.Path).To(Equal("/path/to/src/Api/Api.csproj"))
+		})
+	})
+
+	context("when BP_DOTNET_ENTRY_ASSEMBLY is set", func() {
+		it.Before(func() {
+			detect = dotnetexecute.Detect(dotnetexecute.Configuration{
+				EntryAssembly: "some-app.dll",
+			}, logger, runtimeConfigParser, projectParser, sdkParser)
+		})
+
+		it("looks for the runtimeconfig.json of that assembly", func() {
+			_, err := detect(packit.DetectContext{
+				WorkingDir: workingDir,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(runtimeConfigParser.ParseCall.Receives.Glob).To(Equal(filepath.Join(workingDir, "some-app.runtimeconfig.json")))
+		})
+	})
+
 	context("when BP_LIVE_RELOAD_ENABLED is set to true", func() {
 		it.Before(func() {
 			detect = dotnetexecute.Detect(dotnetexecute.Configuration{
 				LiveReloadEnabled: true,
-			}, logger, runtimeConfigParser, projectParser)
+			}, logger, runtimeConfigParser, projectParser, sdkParser)
 		})
 
 		it("requires watchexec at launch", func() {
@@ -405,11 +844,87 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 
+	context("when BP_LIVE_RELOAD_MODE is source", func() {
+		it.Before(func() {
+			projectParser.FindProjectFileCall.Returns.ProjectSelection = dotnetexecute.ProjectSelection{Path: "/path/to/some-file.csproj"}
+
+			detect = dotnetexecute.Detect(dotnetexecute.Configuration{
+				LiveReloadEnabled: true,
+				LiveReloadMode:    "source",
+			}, logger, runtimeConfigParser, projectParser, sdkParser)
+		})
+
+		it("requires the SDK at launch instead of a published app", func() {
+			result, err := detect(packit.DetectContext{
+				WorkingDir: workingDir,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plan).To(Equal(packit.BuildPlan{
+				Provides: []packit.BuildPlanProvision{{Name: "dotnet-app-kind"}},
+				Requires: []packit.BuildPlanRequirement{
+					{
+						Name: "dotnet-sdk",
+						Metadata: dotnetexecute.BuildPlanMetadata{
+							Launch: true,
+						},
+					},
+					{
+						Name: "icu",
+						Metadata: dotnetexecute.BuildPlanMetadata{
+							Launch: true,
+						},
+					},
+					{
+						Name: "dotnet-app-kind",
+						Metadata: dotnetexecute.BuildPlanMetadata{
+							AppKind: "source",
+						},
+					},
+				},
+			}))
+		})
+
+		context("when the project requires Node", func() {
+			it.Before(func() {
+				projectParser.NodeRequirementCall.Returns.NodeRequirement = dotnetexecute.NodeRequirement{
+					Required: true,
+				}
+			})
+
+			it("requires node at launch as well", func() {
+				result, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Plan.Requires).To(ContainElement(packit.BuildPlanRequirement{
+					Name: "node",
+					Metadata: dotnetexecute.BuildPlanMetadata{
+						Build:  true,
+						Launch: true,
+					},
+				}))
+			})
+		})
+
+		context("when there is no project file", func() {
+			it.Before(func() {
+				projectParser.FindProjectFileCall.Returns.ProjectSelection = dotnetexecute.ProjectSelection{}
+			})
+
+			it("fails detection", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).To(MatchError(packit.Fail.WithMessage("BP_LIVE_RELOAD_MODE=source requires a project file")))
+			})
+		})
+	})
+
 	context("when BP_DEBUG_ENABLED is set to true", func() {
 		it.Before(func() {
 			detect = dotnetexecute.Detect(dotnetexecute.Configuration{
 				DebugEnabled: true,
-			}, logger, runtimeConfigParser, projectParser)
+			}, logger, runtimeConfigParser, projectParser, sdkParser)
 		})
 
 		it("requires vsdbg at launch", func() {
@@ -442,7 +957,22 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 
-		context("there is no *.runtimeconfig.json or project file present", func() {
+		context("when BP_LIVE_RELOAD_MODE is not supported", func() {
+			it.Before(func() {
+				detect = dotnetexecute.Detect(dotnetexecute.Configuration{
+					LiveReloadMode: "magic",
+				}, logger, runtimeConfigParser, projectParser, sdkParser)
+			})
+
+			it("fails", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).To(MatchError(`unsupported BP_LIVE_RELOAD_MODE "magic": must be "binary" or "source"`))
+			})
+		})
+
+		context("there is no *.runtimeconfig.json, app executable or project file present", func() {
 			it.Before(func() {
 				runtimeConfigParser.ParseCall.Returns.RuntimeConfig = dotnetexecute.RuntimeConfig{}
 			})
@@ -451,7 +981,7 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 				_, err := detect(packit.DetectContext{
 					WorkingDir: workingDir,
 				})
-				Expect(err).To(MatchError(packit.Fail.WithMessage("no *.runtimeconfig.json or project file found")))
+				Expect(err).To(MatchError(packit.Fail.WithMessage("no *.runtimeconfig.json, app executable or project file found")))
 			})
 		})
 
@@ -469,10 +999,69 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 
+		context("reading the runtime version from the project file fails", func() {
+			it.Before(func() {
+				projectParser.FindProjectFileCall.Returns.ProjectSelection = dotnetexecute.ProjectSelection{Path: "/path/to/some-file.csproj"}
+				projectParser.RuntimeVersionCall.Returns.Error = errors.New("failed to decode project")
+			})
+
+			it("returns an error", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).To(MatchError("failed to decode project"))
+			})
+		})
+
+		context("reading the package references from the project file fails", func() {
+			it.Before(func() {
+				projectParser.FindProjectFileCall.Returns.ProjectSelection = dotnetexecute.ProjectSelection{Path: "/path/to/some-file.csproj"}
+				projectParser.PackageReferencesCall.Returns.Error = errors.New("failed to decode project")
+			})
+
+			it("returns an error", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).To(MatchError("failed to decode project"))
+			})
+		})
+
+		context("parsing the global.json fails", func() {
+			it.Before(func() {
+				projectParser.FindProjectFileCall.Returns.ProjectSelection = dotnetexecute.ProjectSelection{Path: "/path/to/some-file.csproj"}
+				sdkParser.ParseCall.Returns.Error = errors.New("failed to decode global.json")
+			})
+
+			it("returns an error", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).To(MatchError("failed to decode global.json"))
+			})
+		})
+
+		context("the global.json SDK version is malformed", func() {
+			it.Before(func() {
+				projectParser.FindProjectFileCall.Returns.ProjectSelection = dotnetexecute.ProjectSelection{Path: "/path/to/some-file.csproj"}
+				sdkParser.ParseCall.Returns.GlobalJSON = dotnetexecute.GlobalJSON{
+					Path:       "/path/global.json",
+					SDKVersion: "8",
+				}
+			})
+
+			it("returns an error", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).To(MatchError(ContainSubstring(`failed to parse sdk.version "8"`)))
+			})
+		})
+
 		context("parsing the node requirement from the project file fails", func() {
 			it.Before(func() {
-				projectParser.NodeIsRequiredCall.Returns.Error = errors.New("some-error")
-				projectParser.FindProjectFileCall.Returns.String = "/path/to/some-file.csproj"
+				projectParser.NodeRequirementCall.Returns.Error = errors.New("some-error")
+				projectParser.FindProjectFileCall.Returns.ProjectSelection = dotnetexecute.ProjectSelection{Path: "/path/to/some-file.csproj"}
 			})
 
 			it("fails", func() {
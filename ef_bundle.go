@@ -0,0 +1,85 @@
+package dotnetexecute
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EFBundle is an EF Core migration bundle found in the publish output.
+type EFBundle struct {
+	Path          string
+	SelfContained bool
+}
+
+// FindEFBundle looks for an EF Core migration bundle. When path is set, it
+// names the bundle relative to dir; otherwise the files in dir are matched
+// against the *efbundle naming pattern used by `dotnet ef migrations
+// bundle`. It reports false when no bundle is found and errors when the
+// bundle is ambiguous or cannot be executed.
+func FindEFBundle(dir, path string) (EFBundle, bool, error) {
+	var candidates []string
+	if path != "" {
+		candidates = []string{filepath.Join(dir, path)}
+	} else {
+		matches, err := filepath.Glob(filepath.Join(dir, "*efbundle"))
+		if err != nil {
+			return EFBundle{}, false, err
+		}
+
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return EFBundle{}, false, err
+			}
+
+			if info.Mode().IsRegular() {
+				candidates = append(candidates, match)
+			}
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return EFBundle{}, false, nil
+	case 1:
+	default:
+		return EFBundle{}, false, fmt.Errorf("multiple EF Core migration bundles found: %s: set BP_DOTNET_EF_BUNDLE_PATH to choose one", strings.Join(candidates, ", "))
+	}
+
+	bundle := EFBundle{Path: candidates[0]}
+
+	info, err := os.Stat(bundle.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return EFBundle{}, false, fmt.Errorf("EF Core migration bundle not found: %w", err)
+		}
+		return EFBundle{}, false, err
+	}
+
+	if !info.Mode().IsRegular() || info.Mode()&0111 == 0 {
+		return EFBundle{}, false, fmt.Errorf("EF Core migration bundle %s is not executable", bundle.Path)
+	}
+
+	// A self-contained bundle lists no frameworks in its bundled
+	// runtimeconfig.json; a framework-dependent one relies on the shared
+	// runtime instead.
+	singleFile, ok, err := ReadSingleFileBundle(bundle.Path)
+	if err != nil {
+		return EFBundle{}, false, err
+	}
+
+	if ok && len(singleFile.RuntimeConfig) > 0 {
+		var runtimeConfig RuntimeConfig
+		err = decodeRuntimeConfig(bytes.NewReader(singleFile.RuntimeConfig), &runtimeConfig)
+		if err != nil {
+			return EFBundle{}, false, fmt.Errorf("failed to decode the bundled runtimeconfig.json of %s: %w", bundle.Path, err)
+		}
+		bundle.SelfContained = runtimeConfig.RuntimeVersion == ""
+	}
+
+	return bundle, true, nil
+}
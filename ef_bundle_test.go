@@ -0,0 +1,145 @@
+package dotnetexecute_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	dotnetexecute "github.com/paketo-buildpacks/dotnet-execute"
+	"github.com/sclevine/spec"
+)
+
+func testFindEFBundle(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		workingDir string
+	)
+
+	it.Before(func() {
+		var err error
+		workingDir, err = os.MkdirTemp("", "working-dir")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(workingDir)).To(Succeed())
+	})
+
+	context("when there is no bundle", func() {
+		it("reports that none was found", func() {
+			_, found, err := dotnetexecute.FindEFBundle(workingDir, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+	})
+
+	context("when there is a framework-dependent bundle", func() {
+		it.Before(func() {
+			Expect(writeSingleFileBundle(filepath.Join(workingDir, "efbundle"), 6, 0,
+				bundleFile{Type: 1, Name: "efbundle.dll"},
+				bundleFile{Type: 4, Name: "efbundle.runtimeconfig.json", Content: `{
+					"runtimeOptions": {
+						"framework": {"name": "Microsoft.NETCore.App", "version": "8.0.0"}
+					}
+				}`},
+			)).To(Succeed())
+		})
+
+		it("finds it", func() {
+			bundle, found, err := dotnetexecute.FindEFBundle(workingDir, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(bundle).To(Equal(dotnetexecute.EFBundle{
+				Path: filepath.Join(workingDir, "efbundle"),
+			}))
+		})
+	})
+
+	context("when there is a self-contained bundle", func() {
+		it.Before(func() {
+			Expect(writeSingleFileBundle(filepath.Join(workingDir, "MyApp.efbundle"), 6, 0,
+				bundleFile{Type: 1, Name: "MyApp.efbundle.dll"},
+				bundleFile{Type: 1, Name: "System.Private.CoreLib.dll"},
+				bundleFile{Type: 4, Name: "MyApp.efbundle.runtimeconfig.json", Content: `{
+					"runtimeOptions": {
+						"includedFrameworks": [
+							{"name": "Microsoft.NETCore.App", "version": "8.0.0"}
+						]
+					}
+				}`},
+			)).To(Succeed())
+		})
+
+		it("finds it", func() {
+			bundle, found, err := dotnetexecute.FindEFBundle(workingDir, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(bundle).To(Equal(dotnetexecute.EFBundle{
+				Path:          filepath.Join(workingDir, "MyApp.efbundle"),
+				SelfContained: true,
+			}))
+		})
+	})
+
+	context("when the bundle path is configured", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(workingDir, "tools"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "tools", "migrate"), nil, 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "efbundle"), nil, 0755)).To(Succeed())
+		})
+
+		it("uses that path", func() {
+			bundle, found, err := dotnetexecute.FindEFBundle(workingDir, "tools/migrate")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(bundle.Path).To(Equal(filepath.Join(workingDir, "tools", "migrate")))
+		})
+	})
+
+	context("failure cases", func() {
+		context("when the configured bundle does not exist", func() {
+			it("returns an error", func() {
+				_, _, err := dotnetexecute.FindEFBundle(workingDir, "missing")
+				Expect(err).To(MatchError(ContainSubstring("EF Core migration bundle not found")))
+			})
+		})
+
+		context("when the bundle is not executable", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "efbundle"), nil, 0644)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, _, err := dotnetexecute.FindEFBundle(workingDir, "")
+				Expect(err).To(MatchError(ContainSubstring("is not executable")))
+			})
+		})
+
+		context("when the bundled runtimeconfig.json is malformed", func() {
+			it.Before(func() {
+				Expect(writeSingleFileBundle(filepath.Join(workingDir, "efbundle"), 6, 0,
+					bundleFile{Type: 4, Name: "efbundle.runtimeconfig.json", Content: "%%%"},
+				)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, _, err := dotnetexecute.FindEFBundle(workingDir, "")
+				Expect(err).To(MatchError(ContainSubstring("failed to decode the bundled runtimeconfig.json of %s", filepath.Join(workingDir, "efbundle"))))
+			})
+		})
+
+		context("when there are multiple bundles", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "efbundle"), nil, 0755)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, "other-efbundle"), nil, 0755)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, _, err := dotnetexecute.FindEFBundle(workingDir, "")
+				Expect(err).To(MatchError(ContainSubstring("multiple EF Core migration bundles found")))
+			})
+		})
+	})
+}
@@ -0,0 +1,33 @@
+package fakes
+
+import (
+	"sync"
+
+	dotnetexecute "github.com/paketo-buildpacks/dotnet-execute"
+)
+
+type ProcessParser struct {
+	ParseCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Path string
+		}
+		Returns struct {
+			ProcessDefinitionSlice []dotnetexecute.ProcessDefinition
+			Error                  error
+		}
+		Stub func(string) ([]dotnetexecute.ProcessDefinition, error)
+	}
+}
+
+func (f *ProcessParser) Parse(param1 string) ([]dotnetexecute.ProcessDefinition, error) {
+	f.ParseCall.mutex.Lock()
+	defer f.ParseCall.mutex.Unlock()
+	f.ParseCall.CallCount++
+	f.ParseCall.Receives.Path = param1
+	if f.ParseCall.Stub != nil {
+		return f.ParseCall.Stub(param1)
+	}
+	return f.ParseCall.Returns.ProcessDefinitionSlice, f.ParseCall.Returns.Error
+}
@@ -1,6 +1,10 @@
 package fakes
 
-import "sync"
+import (
+	"sync"
+
+	dotnetexecute "github.com/paketo-buildpacks/dotnet-execute"
+)
 
 type ProjectParser struct {
 	FindProjectFileCall struct {
@@ -8,44 +12,122 @@ type ProjectParser struct {
 		CallCount int
 		Receives  struct {
 			Root string
+			Dir  string
+			Name string
 		}
 		Returns struct {
-			String string
-			Error  error
+			ProjectSelection dotnetexecute.ProjectSelection
+			Error            error
+		}
+		Stub func(string, string, string) (dotnetexecute.ProjectSelection, error)
+	}
+	ImageMetadataCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Root string
+			Path string
+		}
+		Returns struct {
+			ImageMetadata dotnetexecute.ImageMetadata
+			Error         error
+		}
+		Stub func(string, string) (dotnetexecute.ImageMetadata, error)
+	}
+	NodeRequirementCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Root string
+			Path string
+		}
+		Returns struct {
+			NodeRequirement dotnetexecute.NodeRequirement
+			Error           error
+		}
+		Stub func(string, string) (dotnetexecute.NodeRequirement, error)
+	}
+	PackageReferencesCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Root string
+			Path string
+		}
+		Returns struct {
+			StringSlice []string
+			Error       error
 		}
-		Stub func(string) (string, error)
+		Stub func(string, string) ([]string, error)
 	}
-	NodeIsRequiredCall struct {
+	RuntimeVersionCall struct {
 		mutex     sync.Mutex
 		CallCount int
 		Receives  struct {
+			Root string
 			Path string
 		}
 		Returns struct {
-			Bool  bool
-			Error error
+			String string
+			Error  error
 		}
-		Stub func(string) (bool, error)
+		Stub func(string, string) (string, error)
 	}
 }
 
-func (f *ProjectParser) FindProjectFile(param1 string) (string, error) {
+func (f *ProjectParser) FindProjectFile(param1 string, param2 string, param3 string) (dotnetexecute.ProjectSelection, error) {
 	f.FindProjectFileCall.mutex.Lock()
 	defer f.FindProjectFileCall.mutex.Unlock()
 	f.FindProjectFileCall.CallCount++
 	f.FindProjectFileCall.Receives.Root = param1
+	f.FindProjectFileCall.Receives.Dir = param2
+	f.FindProjectFileCall.Receives.Name = param3
 	if f.FindProjectFileCall.Stub != nil {
-		return f.FindProjectFileCall.Stub(param1)
+		return f.FindProjectFileCall.Stub(param1, param2, param3)
+	}
+	return f.FindProjectFileCall.Returns.ProjectSelection, f.FindProjectFileCall.Returns.Error
+}
+func (f *ProjectParser) ImageMetadata(param1 string, param2 string) (dotnetexecute.ImageMetadata, error) {
+	f.ImageMetadataCall.mutex.Lock()
+	defer f.ImageMetadataCall.mutex.Unlock()
+	f.ImageMetadataCall.CallCount++
+	f.ImageMetadataCall.Receives.Root = param1
+	f.ImageMetadataCall.Receives.Path = param2
+	if f.ImageMetadataCall.Stub != nil {
+		return f.ImageMetadataCall.Stub(param1, param2)
 	}
-	return f.FindProjectFileCall.Returns.String, f.FindProjectFileCall.Returns.Error
+	return f.ImageMetadataCall.Returns.ImageMetadata, f.ImageMetadataCall.Returns.Error
 }
-func (f *ProjectParser) NodeIsRequired(param1 string) (bool, error) {
-	f.NodeIsRequiredCall.mutex.Lock()
-	defer f.NodeIsRequiredCall.mutex.Unlock()
-	f.NodeIsRequiredCall.CallCount++
-	f.NodeIsRequiredCall.Receives.Path = param1
-	if f.NodeIsRequiredCall.Stub != nil {
-		return f.NodeIsRequiredCall.Stub(param1)
-	}
-	return f.NodeIsRequiredCall.Returns.Bool, f.NodeIsRequiredCall.Returns.Error
+func (f *ProjectParser) NodeRequirement(param1 string, param2 string) (dotnetexecute.NodeRequirement, error) {
+	f.NodeRequirementCall.mutex.Lock()
+	defer f.NodeRequirementCall.mutex.Unlock()
+	f.NodeRequirementCall.CallCount++
+	f.NodeRequirementCall.Receives.Root = param1
+	f.NodeRequirementCall.Receives.Path = param2
+	if f.NodeRequirementCall.Stub != nil {
+		return f.NodeRequirementCall.Stub(param1, param2)
+	}
+	return f.NodeRequirementCall.Returns.NodeRequirement, f.NodeRequirementCall.Returns.Error
+}
+func (f *ProjectParser) PackageReferences(param1 string, param2 string) ([]string, error) {
+	f.PackageReferencesCall.mutex.Lock()
+	defer f.PackageReferencesCall.mutex.Unlock()
+	f.PackageReferencesCall.CallCount++
+	f.PackageReferencesCall.Receives.Root = param1
+	f.PackageReferencesCall.Receives.Path = param2
+	if f.PackageReferencesCall.Stub != nil {
+		return f.PackageReferencesCall.Stub(param1, param2)
+	}
+	return f.PackageReferencesCall.Returns.StringSlice, f.PackageReferencesCall.Returns.Error
+}
+func (f *ProjectParser) RuntimeVersion(param1 string, param2 string) (string, error) {
+	f.RuntimeVersionCall.mutex.Lock()
+	defer f.RuntimeVersionCall.mutex.Unlock()
+	f.RuntimeVersionCall.CallCount++
+	f.RuntimeVersionCall.Receives.Root = param1
+	f.RuntimeVersionCall.Receives.Path = param2
+	if f.RuntimeVersionCall.Stub != nil {
+		return f.RuntimeVersionCall.Stub(param1, param2)
+	}
+	return f.RuntimeVersionCall.Returns.String, f.RuntimeVersionCall.Returns.Error
 }
@@ -0,0 +1,35 @@
+package fakes
+
+import (
+	"sync"
+
+	dotnetexecute "github.com/paketo-buildpacks/dotnet-execute"
+)
+
+type SDKParser struct {
+	ParseCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Root string
+			Dir  string
+		}
+		Returns struct {
+			GlobalJSON dotnetexecute.GlobalJSON
+			Error      error
+		}
+		Stub func(string, string) (dotnetexecute.GlobalJSON, error)
+	}
+}
+
+func (f *SDKParser) Parse(param1 string, param2 string) (dotnetexecute.GlobalJSON, error) {
+	f.ParseCall.mutex.Lock()
+	defer f.ParseCall.mutex.Unlock()
+	f.ParseCall.CallCount++
+	f.ParseCall.Receives.Root = param1
+	f.ParseCall.Receives.Dir = param2
+	if f.ParseCall.Stub != nil {
+		return f.ParseCall.Stub(param1, param2)
+	}
+	return f.ParseCall.Returns.GlobalJSON, f.ParseCall.Returns.Error
+}
@@ -0,0 +1,117 @@
+package dotnetexecute
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gravityblast/go-jsmin"
+)
+
+// GlobalJSON holds the SDK pin from a global.json file.
+type GlobalJSON struct {
+	Path            string
+	SDKVersion      string
+	RollForward     string
+	AllowPrerelease bool
+}
+
+// RuntimeConstraint turns the SDK pin into a constraint on the .NET runtime
+// that the SDK targets. An SDK in feature band 8.0.1xx ships the 8.0
+// runtime, so by default the constraint allows any 8.0 patch. A rollForward
+// policy that lets the SDK move to a later minor or major version widens the
+// constraint to match. It returns an empty string when no SDK version is
+// pinned.
+func (g GlobalJSON) RuntimeConstraint() (string, error) {
+	if g.SDKVersion == "" {
+		return "", nil
+	}
+
+	version, prerelease, _ := strings.Cut(g.SDKVersion, "-")
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("failed to parse sdk.version %q in %s: expected major.minor.patch", g.SDKVersion, g.Path)
+	}
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return "", fmt.Errorf("failed to parse sdk.version %q in %s: %w", g.SDKVersion, g.Path, err)
+	}
+
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("failed to parse sdk.version %q in %s: %w", g.SDKVersion, g.Path, err)
+	}
+
+	// Constraints only match prerelease versions when they name one.
+	suffix := ""
+	if prerelease != "" || g.AllowPrerelease {
+		suffix = "-0"
+	}
+
+	switch strings.ToLower(g.RollForward) {
+	case "minor", "latestminor":
+		return fmt.Sprintf(">= %d.%d.0%s, < %d.0.0%s", major, minor, suffix, major+1, suffix), nil
+	case "major", "latestmajor":
+		return fmt.Sprintf(">= %d.%d.0%s", major, minor, suffix), nil
+	}
+
+	if suffix != "" {
+		return fmt.Sprintf("~%d.%d.0%s", major, minor, suffix), nil
+	}
+
+	return fmt.Sprintf("%d.%d.*", major, minor), nil
+}
+
+type GlobalJSONParser struct{}
+
+func NewGlobalJSONParser() GlobalJSONParser {
+	return GlobalJSONParser{}
+}
+
+// Parse reads the nearest global.json in dir or one of its parents up to
+// root. It returns an empty GlobalJSON when there is none.
+func (p GlobalJSONParser) Parse(root, dir string) (GlobalJSON, error) {
+	path := findFileAbove(root, dir, "global.json")
+	if path == "" {
+		return GlobalJSON{}, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return GlobalJSON{}, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	buffer := bytes.NewBuffer(nil)
+	err = jsmin.Min(file, buffer)
+	if err != nil {
+		return GlobalJSON{}, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	var data struct {
+		SDK struct {
+			Version         string `json:"version"`
+			RollForward     string `json:"rollForward"`
+			AllowPrerelease bool   `json:"allowPrerelease"`
+		} `json:"sdk"`
+	}
+
+	err = json.NewDecoder(buffer).Decode(&data)
+	if err != nil {
+		return GlobalJSON{}, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	return GlobalJSON{
+		Path:            filepath.Clean(path),
+		SDKVersion:      data.SDK.Version,
+		RollForward:     data.SDK.RollForward,
+		AllowPrerelease: data.SDK.AllowPrerelease,
+	}, nil
+}
@@ -0,0 +1,119 @@
+package dotnetexecute_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	dotnetexecute "github.com/paketo-buildpacks/dotnet-execute"
+	"github.com/sclevine/spec"
+)
+
+func testGlobalJSONParser(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		workingDir string
+		parser     dotnetexecute.GlobalJSONParser
+	)
+
+	it.Before(func() {
+		var err error
+		workingDir, err = os.MkdirTemp("", "working-dir")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(workingDir, "src", "app"), os.ModePerm)).To(Succeed())
+
+		parser = dotnetexecute.NewGlobalJSONParser()
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(workingDir)).To(Succeed())
+	})
+
+	context("Parse", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "global.json"), []byte(`{
+				// comments are allowed
+				"sdk": {
+					"version": "8.0.204",
+					"rollForward": "latestFeature",
+					"allowPrerelease": false
+				}
+			}`), 0600)).To(Succeed())
+		})
+
+		it("reads the nearest global.json above the directory", func() {
+			globalJSON, err := parser.Parse(workingDir, filepath.Join(workingDir, "src", "app"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(globalJSON).To(Equal(dotnetexecute.GlobalJSON{
+				Path:        filepath.Join(workingDir, "global.json"),
+				SDKVersion:  "8.0.204",
+				RollForward: "latestFeature",
+			}))
+		})
+
+		context("when the global.json is above the app root", func() {
+			it("does not read it", func() {
+				globalJSON, err := parser.Parse(filepath.Join(workingDir, "src"), filepath.Join(workingDir, "src", "app"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(globalJSON).To(Equal(dotnetexecute.GlobalJSON{}))
+			})
+		})
+
+		context("when there is no global.json", func() {
+			it.Before(func() {
+				Expect(os.Remove(filepath.Join(workingDir, "global.json"))).To(Succeed())
+			})
+
+			it("returns an empty GlobalJSON", func() {
+				globalJSON, err := parser.Parse(workingDir, filepath.Join(workingDir, "src", "app"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(globalJSON).To(Equal(dotnetexecute.GlobalJSON{}))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the global.json can not be decoded", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(workingDir, "global.json"), []byte("%%%"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := parser.Parse(workingDir, workingDir)
+					Expect(err).To(MatchError(ContainSubstring("failed to decode")))
+				})
+			})
+		})
+	})
+
+	context("RuntimeConstraint", func() {
+		it("maps the SDK pin to a runtime constraint", func() {
+			for _, c := range []struct {
+				globalJSON dotnetexecute.GlobalJSON
+				constraint string
+			}{
+				{dotnetexecute.GlobalJSON{}, ""},
+				{dotnetexecute.GlobalJSON{SDKVersion: "8.0.204"}, "8.0.*"},
+				{dotnetexecute.GlobalJSON{SDKVersion: "8.0.204", RollForward: "disable"}, "8.0.*"},
+				{dotnetexecute.GlobalJSON{SDKVersion: "8.0.204", RollForward: "latestFeature"}, "8.0.*"},
+				{dotnetexecute.GlobalJSON{SDKVersion: "8.0.204", RollForward: "latestMinor"}, ">= 8.0.0, < 9.0.0"},
+				{dotnetexecute.GlobalJSON{SDKVersion: "6.0.100", RollForward: "major"}, ">= 6.0.0"},
+				{dotnetexecute.GlobalJSON{SDKVersion: "9.0.100-rc.2.24474.11"}, "~9.0.0-0"},
+				{dotnetexecute.GlobalJSON{SDKVersion: "8.0.100", AllowPrerelease: true, RollForward: "latestMajor"}, ">= 8.0.0-0"},
+			} {
+				constraint, err := c.globalJSON.RuntimeConstraint()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(constraint).To(Equal(c.constraint), c.globalJSON.SDKVersion+" "+c.globalJSON.RollForward)
+			}
+		})
+
+		context("when the SDK version is malformed", func() {
+			it("returns an error", func() {
+				_, err := dotnetexecute.GlobalJSON{Path: "global.json", SDKVersion: "eight.0.100"}.RuntimeConstraint()
+				Expect(err).To(MatchError(ContainSubstring(`failed to parse sdk.version "eight.0.100" in global.json`)))
+			})
+		})
+	})
+}
@@ -0,0 +1,175 @@
+package dotnetexecute
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// ImageMetadata is the app metadata that is turned into OCI image labels.
+type ImageMetadata struct {
+	Version  string
+	Title    string
+	Authors  string
+	Source   string
+	Revision string
+	Licenses string
+}
+
+// Labels returns the org.opencontainers.image.* labels for the metadata that
+// is set.
+func (m ImageMetadata) Labels() map[string]string {
+	labels := map[string]string{}
+	for name, value := range map[string]string{
+		"version":  m.Version,
+		"title":    m.Title,
+		"authors":  m.Authors,
+		"source":   m.Source,
+		"revision": m.Revision,
+		"licenses": m.Licenses,
+	} {
+		if value != "" {
+			labels["org.opencontainers.image."+name] = value
+		}
+	}
+
+	return labels
+}
+
+// ReadAssemblyMetadata reads image metadata from a published assembly. The
+// version, title and revision come from the ProductVersion and ProductName
+// version resources, where the SDK appends SourceRevisionId to the version
+// after a "+". The authors come from the CompanyName version resource, unless
+// it is the assembly name that the SDK uses when Company is not set. The
+// source comes from the RepositoryUrl assembly metadata that Source Link
+// adds, and the licenses from PackageLicenseExpression assembly metadata. A
+// file that is not a PE file has no metadata.
+func ReadAssemblyMetadata(path string) (ImageMetadata, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ImageMetadata{}, nil
+		}
+		return ImageMetadata{}, err
+	}
+
+	if !bytes.HasPrefix(content, []byte("MZ")) {
+		return ImageMetadata{}, nil
+	}
+
+	version, revision, _ := strings.Cut(versionResourceString(content, "ProductVersion"), "+")
+
+	authors := versionResourceString(content, "CompanyName")
+	if authors == strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)) {
+		authors = ""
+	}
+
+	return ImageMetadata{
+		Version:  version,
+		Title:    versionResourceString(content, "ProductName"),
+		Authors:  authors,
+		Source:   assemblyMetadataValue(content, "RepositoryUrl"),
+		Revision: revision,
+		Licenses: assemblyMetadataValue(content, "PackageLicenseExpression"),
+	}, nil
+}
+
+// versionResourceString finds a string in the VS_VERSIONINFO resource of a
+// PE file. Each string is stored as a UTF-16 key, padding and a UTF-16
+// value, all NUL-terminated.
+func versionResourceString(content []byte, key string) string {
+	marker := encodeUTF16(key + "\x00")
+	index := bytes.Index(content, marker)
+	if index < 0 {
+		return ""
+	}
+
+	rest := content[index+len(marker):]
+	for len(rest) >= 2 && rest[0] == 0 && rest[1] == 0 {
+		rest = rest[2:]
+	}
+
+	var units []uint16
+	for ; len(rest) >= 2; rest = rest[2:] {
+		unit := uint16(rest[0]) | uint16(rest[1])<<8
+		if unit == 0 {
+			break
+		}
+		units = append(units, unit)
+	}
+
+	// Surrogate pairs are only printable once they are decoded together.
+	value := utf16.Decode(units)
+	for i, char := range value {
+		if !unicode.IsPrint(char) {
+			return string(value[:i])
+		}
+	}
+
+	return string(value)
+}
+
+// assemblyMetadataValue finds the value of an [AssemblyMetadata(key, value)]
+// attribute, whose blob holds the key and value as length-prefixed UTF-8
+// strings.
+func assemblyMetadataValue(content []byte, key string) string {
+	marker := append([]byte{byte(len(key))}, key...)
+	index := bytes.Index(content, marker)
+	if index < 0 {
+		return ""
+	}
+
+	rest := content[index+len(marker):]
+	if len(rest) == 0 {
+		return ""
+	}
+
+	// Lengths below 0x80 take one byte, and lengths below 0x4000 two.
+	length, size := int(rest[0]), 1
+	if rest[0]&0x80 != 0 {
+		if rest[0]&0xc0 != 0x80 || len(rest) < 2 {
+			return ""
+		}
+		length, size = int(rest[0]&0x3f)<<8|int(rest[1]), 2
+	}
+
+	if len(rest) < size+length {
+		return ""
+	}
+
+	return string(rest[size : size+length])
+}
+
+func encodeUTF16(value string) []byte {
+	var encoded []byte
+	for _, char := range utf16.Encode([]rune(value)) {
+		encoded = append(encoded, byte(char), byte(char>>8))
+	}
+
+	return encoded
+}
+
+// ParseImageLabels parses labels given as shell-quoted key=value pairs, such
+// as `com.example.team=payments "com.example.tier=back end"`.
+func ParseImageLabels(value string) (map[string]string, error) {
+	pairs, err := ParseArgs(value)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := map[string]string{}
+	for _, pair := range pairs {
+		key, label, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid image label %q: expected key=value", pair)
+		}
+		labels[key] = label
+	}
+
+	return labels, nil
+}
@@ -0,0 +1,183 @@
+package dotnetexecute_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+
+	dotnetexecute "github.com/paketo-buildpacks/dotnet-execute"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+// writeAssemblyMetadata writes an assembly stand-in holding version resource
+// strings and an [AssemblyMetadata("RepositoryUrl", ...)] attribute blob.
+func writeAssemblyMetadata(path string, resources, metadata map[string]string) error {
+	writeUTF16 := func(buffer *bytes.Buffer, value string) {
+		for _, char := range utf16.Encode([]rune(value + "\x00")) {
+			buffer.Write([]byte{byte(char), byte(char >> 8)})
+		}
+	}
+
+	assembly := bytes.NewBuffer([]byte("MZ\x90\x00 assembly code"))
+	for key, value := range metadata {
+		assembly.Write([]byte{0x01, 0x00, byte(len(key))})
+		assembly.WriteString(key)
+		assembly.WriteByte(byte(len(value)))
+		assembly.WriteString(value)
+		assembly.Write([]byte{0x00, 0x00})
+	}
+
+	for key, value := range resources {
+		assembly.Write([]byte{0x40, 0x00, 0x10, 0x00, 0x01, 0x00})
+		writeUTF16(assembly, key)
+		if len(key)%2 == 1 {
+			assembly.Write([]byte{0x00, 0x00})
+		}
+		writeUTF16(assembly, value)
+	}
+
+	return os.WriteFile(path, assembly.Bytes(), 0600)
+}
+
+func testImageLabels(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("ReadAssemblyMetadata", func() {
+		var path string
+
+		it.Before(func() {
+			dir, err := os.MkdirTemp("", "assembly")
+			Expect(err).NotTo(HaveOccurred())
+
+			path = filepath.Join(dir, "my.app.dll")
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(filepath.Dir(path))).To(Succeed())
+		})
+
+		it("reads the version resources and assembly metadata", func() {
+			Expect(writeAssemblyMetadata(path, map[string]string{
+				"CompanyName":    "Example",
+				"ProductName":    "My App",
+				"ProductVersion": "1.2.3+0123abcd",
+			}, map[string]string{
+				"RepositoryUrl":            "https://github.com/example/my-app",
+				"PackageLicenseExpression": "Apache-2.0",
+			})).To(Succeed())
+
+			metadata, err := dotnetexecute.ReadAssemblyMetadata(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(metadata).To(Equal(dotnetexecute.ImageMetadata{
+				Version:  "1.2.3",
+				Title:    "My App",
+				Authors:  "Example",
+				Source:   "https://github.com/example/my-app",
+				Revision: "0123abcd",
+				Licenses: "Apache-2.0",
+			}))
+		})
+
+		context("when the company is the assembly name", func() {
+			it.Before(func() {
+				Expect(writeAssemblyMetadata(path, map[string]string{
+					"CompanyName": "my.app",
+				}, nil)).To(Succeed())
+			})
+
+			it("does not use it as the authors", func() {
+				metadata, err := dotnetexecute.ReadAssemblyMetadata(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(metadata.Authors).To(BeEmpty())
+			})
+		})
+
+		context("when a version resource has characters outside of the BMP", func() {
+			it.Before(func() {
+				Expect(writeAssemblyMetadata(path, map[string]string{
+					"ProductName": "My App \U0001F680",
+				}, nil)).To(Succeed())
+			})
+
+			it("keeps them", func() {
+				metadata, err := dotnetexecute.ReadAssemblyMetadata(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(metadata.Title).To(Equal("My App \U0001F680"))
+			})
+		})
+
+		context("when the assembly has no metadata", func() {
+			it.Before(func() {
+				Expect(writeAssemblyMetadata(path, nil, nil)).To(Succeed())
+			})
+
+			it("returns empty metadata", func() {
+				metadata, err := dotnetexecute.ReadAssemblyMetadata(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(metadata).To(Equal(dotnetexecute.ImageMetadata{}))
+			})
+		})
+
+		context("when the file is not an assembly", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte("ProductName"), 0600)).To(Succeed())
+			})
+
+			it("returns empty metadata", func() {
+				metadata, err := dotnetexecute.ReadAssemblyMetadata(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(metadata).To(Equal(dotnetexecute.ImageMetadata{}))
+			})
+		})
+
+		context("when the file does not exist", func() {
+			it("returns empty metadata", func() {
+				metadata, err := dotnetexecute.ReadAssemblyMetadata(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(metadata).To(Equal(dotnetexecute.ImageMetadata{}))
+			})
+		})
+	})
+
+	context("Labels", func() {
+		it("returns OCI labels for the metadata that is set", func() {
+			Expect(dotnetexecute.ImageMetadata{
+				Version:  "1.2.3",
+				Title:    "My App",
+				Licenses: "MIT",
+			}.Labels()).To(Equal(map[string]string{
+				"org.opencontainers.image.version":  "1.2.3",
+				"org.opencontainers.image.title":    "My App",
+				"org.opencontainers.image.licenses": "MIT",
+			}))
+		})
+	})
+
+	context("ParseImageLabels", func() {
+		it("parses key=value pairs", func() {
+			labels, err := dotnetexecute.ParseImageLabels(`com.example.team=payments "com.example.tier=back end" com.example.empty=`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(labels).To(Equal(map[string]string{
+				"com.example.team":  "payments",
+				"com.example.tier":  "back end",
+				"com.example.empty": "",
+			}))
+		})
+
+		context("failure cases", func() {
+			it("errors on a pair without a key", func() {
+				_, err := dotnetexecute.ParseImageLabels("=payments")
+				Expect(err).To(MatchError(`invalid image label "=payments": expected key=value`))
+			})
+
+			it("errors on a value without =", func() {
+				_, err := dotnetexecute.ParseImageLabels("payments")
+				Expect(err).To(MatchError(`invalid image label "payments": expected key=value`))
+			})
+		})
+	})
+}
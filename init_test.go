@@ -13,5 +13,20 @@ func TestUnitDotnetExecute(t *testing.T) {
 	suite("Detect", testDetect)
 	suite("RuntimeConfigParser", testRuntimeConfigParser)
 	suite("ProjectFileParser", testProjectFileParser)
+	suite("ParseArgs", testParseArgs)
+	suite("ProcessDescriptorParser", testProcessDescriptorParser)
+	suite("FindEFBundle", testFindEFBundle)
+	suite("LoadMSBuildProject", testLoadMSBuildProject)
+	suite("GlobalJSONParser", testGlobalJSONParser)
+	suite("FindNativeLibraries", testFindNativeLibraries)
+	suite("FindUnresolvedLibraries", testFindUnresolvedLibraries)
+	suite("FindOTelAutoInstrumentation", testFindOTelAutoInstrumentation)
+	suite("ReadStartupHook", testReadStartupHook)
+	suite("FindLayerStartupHooks", testFindLayerStartupHooks)
+	suite("ReadSingleFileBundle", testReadSingleFileBundle)
+	suite("ImageLabels", testImageLabels)
+	suite("AppKind", testAppKind)
+	suite("ValidatePublishOutput", testValidatePublishOutput)
+	suite("Report", testReport)
 	suite.Run(t)
 }
@@ -87,6 +87,11 @@ func testLogging(t *testing.T, context spec.G, it spec.S) {
 				"    Available to other buildpacks: false",
 				"    Cached for rebuilds: false",
 				"",
+				"  Setting up layer 'launch-helpers'",
+				"    Available at app launch: true",
+				"    Available to other buildpacks: false",
+				"    Cached for rebuilds: false",
+				"",
 			))
 		})
 	})
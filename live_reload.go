@@ -0,0 +1,268 @@
+package dotnetexecute
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// DefaultLiveReloadWatch restarts the app when its compiled output or its
+	// configuration changes.
+	DefaultLiveReloadWatch = "*.dll,*.json,*.config"
+
+	// DefaultLiveReloadIgnore keeps static asset changes from restarting the
+	// app.
+	DefaultLiveReloadIgnore = "wwwroot/**"
+)
+
+// LiveReloadPatterns holds the watchexec filters derived from the
+// BP_LIVE_RELOAD_WATCH and BP_LIVE_RELOAD_IGNORE configuration.
+type LiveReloadPatterns struct {
+	Root    string
+	Watch   []string
+	Filters []string
+	Ignore  []string
+}
+
+// NewLiveReloadPatterns splits the configured watch patterns into watched
+// paths, which are resolved relative to root, and glob filters such as *.dll
+// or src/**/*.cs. Extension patterns are filters like any other glob, as
+// watchexec only passes a change that matches both --exts and --filter.
+func NewLiveReloadPatterns(config Configuration, root string) LiveReloadPatterns {
+	watch := config.LiveReloadWatch
+	if watch == "" {
+		watch = DefaultLiveReloadWatch
+	}
+
+	ignore := config.LiveReloadIgnore
+	if ignore == "" {
+		ignore = DefaultLiveReloadIgnore
+	}
+
+	patterns := LiveReloadPatterns{Root: root}
+	for _, entry := range splitList(watch) {
+		if strings.ContainsAny(entry, "*?[") {
+			patterns.Filters = append(patterns.Filters, entry)
+			continue
+		}
+
+		patterns.Watch = append(patterns.Watch, filepath.Join(root, entry))
+	}
+
+	patterns.Ignore = splitList(ignore)
+
+	return patterns
+}
+
+// Args returns the watchexec arguments that apply the patterns. When no
+// paths are given, the whole of the root is watched.
+func (p LiveReloadPatterns) Args() []string {
+	watch := p.Watch
+	if len(watch) == 0 {
+		watch = []string{p.Root}
+	}
+
+	var args []string
+	for _, path := range watch {
+		args = append(args, "--watch", path)
+	}
+
+	for _, pattern := range p.Filters {
+		args = append(args, "--filter", pattern)
+	}
+
+	for _, pattern := range p.Ignore {
+		args = append(args, "--ignore", pattern)
+	}
+
+	return args
+}
+
+// Matches reports whether a change to the file at path, relative to the
+// root, restarts the app. Like watchexec, a change restarts the app when it
+// matches any of the filters, and without filters every file does.
+func (p LiveReloadPatterns) Matches(path string) bool {
+	if len(p.Filters) == 0 {
+		return true
+	}
+
+	path = filepath.ToSlash(path)
+
+	for _, filter := range p.Filters {
+		if matchGlob(filter, path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchGlob reports whether the slash-separated path matches pattern, in
+// which ** stands for any number of directories. Like watchexec, a pattern
+// without a slash is matched against the file name alone.
+func matchGlob(pattern, path string) bool {
+	if !strings.Contains(pattern, "/") {
+		matched, _ := filepath.Match(pattern, filepath.Base(path))
+		return matched
+	}
+
+	return matchGlobSegments(strings.Split(pattern, "/"), strings.Split(path, "/"))
+}
+
+func matchGlobSegments(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(path); i++ {
+				if matchGlobSegments(pattern[1:], path[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(path) == 0 {
+			return false
+		}
+
+		matched, _ := filepath.Match(pattern[0], path[0])
+		if !matched {
+			return false
+		}
+
+		pattern, path = pattern[1:], path[1:]
+	}
+
+	return len(path) == 0
+}
+
+// PermissionSummary counts the changes made by GrantGroupReadWrite.
+type PermissionSummary struct {
+	Files       int
+	Directories int
+	Skipped     []string
+}
+
+// GrantGroupReadWrite makes files group read/writable so that a file sync
+// tool running as a different user in the same group can update them. Under
+// the watched paths, or the root when no paths are watched, only the files
+// that the patterns match are changed, along with the directories that hold
+// them. Every file and directory under writablePaths is changed. Paths that
+// do not exist are ignored. Symlinks are never modified and a path that
+// resolves outside of the root is skipped.
+func GrantGroupReadWrite(patterns LiveReloadPatterns, writablePaths []string) (PermissionSummary, error) {
+	var summary PermissionSummary
+
+	resolvedRoot, err := filepath.EvalSymlinks(patterns.Root)
+	if err != nil {
+		return PermissionSummary{}, err
+	}
+
+	visited := map[string]bool{}
+	grant := func(path string, mode fs.FileMode) error {
+		if path == resolvedRoot || visited[path] {
+			return nil
+		}
+		visited[path] = true
+
+		info, err := os.Lstat(path)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			summary.Directories++
+		} else {
+			summary.Files++
+		}
+
+		return os.Chmod(path, info.Mode().Perm()|mode)
+	}
+
+	walk := func(path string, match func(path string) bool) error {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+
+		if !withinDir(resolvedRoot, resolved) {
+			summary.Skipped = append(summary.Skipped, path)
+			return nil
+		}
+
+		return filepath.WalkDir(resolved, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if entry.Type()&fs.ModeSymlink != 0 {
+				return nil
+			}
+
+			rel, err := filepath.Rel(resolvedRoot, path)
+			if err != nil {
+				return err
+			}
+
+			if entry.IsDir() {
+				if match == nil {
+					return grant(path, 0070)
+				}
+				return nil
+			}
+
+			if match != nil && !match(rel) {
+				return nil
+			}
+
+			// The directories between the walked path and a matching file
+			// need to be searchable and writable for a sync tool to replace
+			// the file.
+			if match != nil {
+				for dir := filepath.Dir(path); withinDir(resolved, dir) && dir != resolvedRoot; dir = filepath.Dir(dir) {
+					err = grant(dir, 0070)
+					if err != nil {
+						return err
+					}
+				}
+			}
+
+			return grant(path, 0060)
+		})
+	}
+
+	watched := patterns.Watch
+	if len(watched) == 0 {
+		watched = []string{patterns.Root}
+	}
+
+	for _, path := range watched {
+		err = walk(path, patterns.Matches)
+		if err != nil {
+			return PermissionSummary{}, err
+		}
+	}
+
+	for _, path := range writablePaths {
+		err = walk(path, nil)
+		if err != nil {
+			return PermissionSummary{}, err
+		}
+	}
+
+	return summary, nil
+}
+
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,608 @@
+package dotnetexecute
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// MSBuildProject is a best-effort evaluation of a project file together with
+// the Directory.Build.props, Directory.Build.targets and <Import> files that
+// it pulls in. It understands $(Property) references and simple conditions,
+// which covers how most repositories share settings between projects. It
+// does not load MSBuild SDKs or run property functions.
+type MSBuildProject struct {
+	// Path is the project file that was loaded.
+	Path string
+
+	// SDKs lists the MSBuild SDKs that the project uses, such as
+	// Microsoft.NET.Sdk.Web, without their versions.
+	SDKs []string
+
+	// Files lists every file that was evaluated, in evaluation order.
+	Files []string
+
+	// PackageReferences lists the NuGet packages that the project references.
+	PackageReferences []string
+
+	// ExecCommands lists the commands of the Exec tasks in the project's
+	// targets.
+	ExecCommands []string
+
+	properties map[string]string
+}
+
+// Property returns the value of the named property, or an empty string if it
+// is not set. Property names are case-insensitive.
+func (p MSBuildProject) Property(name string) string {
+	return p.properties[strings.ToLower(name)]
+}
+
+// HasPackageReference reports whether the project references the named
+// NuGet package.
+func (p MSBuildProject) HasPackageReference(name string) bool {
+	for _, reference := range p.PackageReferences {
+		if strings.EqualFold(reference, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LoadMSBuildProject evaluates the project file at path. The nearest
+// Directory.Build.props and Directory.Build.targets found by walking up from
+// the project's directory to root are evaluated before and after the
+// project, as MSBuild does. Imports are followed too, but files outside of
+// root are never read. Configuration defaults to Release, which is what the
+// app is published with.
+func LoadMSBuildProject(root, path string) (MSBuildProject, error) {
+	document, err := decodeMSBuildFile(path)
+	if err != nil {
+		return MSBuildProject{}, err
+	}
+
+	e := &msbuildEvaluator{
+		root: root,
+		project: MSBuildProject{
+			Path: path,
+			properties: map[string]string{
+				"configuration":           "Release",
+				"msbuildprojectdirectory": filepath.Dir(path),
+				"msbuildprojectfile":      filepath.Base(path),
+				"msbuildprojectname":      strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+			},
+		},
+		visited: map[string]bool{},
+	}
+
+	sdks := splitMSBuildList(document.Attr("Sdk"))
+	for _, element := range document.Children {
+		if element.XMLName.Local == "Sdk" {
+			sdks = append(sdks, element.Attr("Name"))
+		}
+	}
+
+	for _, sdk := range sdks {
+		name, _, _ := strings.Cut(sdk, "/")
+		e.project.SDKs = append(e.project.SDKs, strings.TrimSpace(name))
+	}
+
+	if props := findFileAbove(root, filepath.Dir(path), "Directory.Build.props"); props != "" {
+		err = e.importFile(props)
+		if err != nil {
+			return MSBuildProject{}, err
+		}
+	}
+
+	e.visited[path] = true
+	err = e.evaluate(path, document)
+	if err != nil {
+		return MSBuildProject{}, err
+	}
+
+	if targets := findFileAbove(root, filepath.Dir(path), "Directory.Build.targets"); targets != "" {
+		err = e.importFile(targets)
+		if err != nil {
+			return MSBuildProject{}, err
+		}
+	}
+
+	// Items and targets are evaluated once all properties are known.
+	for _, d := range e.deferred {
+		e.evaluateItemsAndTargets(d.path, d.element)
+	}
+
+	return e.project, nil
+}
+
+type msbuildElement struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr       `xml:",any,attr"`
+	Value    string           `xml:",chardata"`
+	Children []msbuildElement `xml:",any"`
+}
+
+func (e msbuildElement) Attr(name string) string {
+	for _, attr := range e.Attrs {
+		if strings.EqualFold(attr.Name.Local, name) {
+			return attr.Value
+		}
+	}
+
+	return ""
+}
+
+func decodeMSBuildFile(path string) (msbuildElement, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return msbuildElement{}, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var document msbuildElement
+	err = xml.NewDecoder(file).Decode(&document)
+	if err != nil {
+		return msbuildElement{}, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	return document, nil
+}
+
+type deferredElement struct {
+	path    string
+	element msbuildElement
+}
+
+type msbuildEvaluator struct {
+	root     string
+	project  MSBuildProject
+	visited  map[string]bool
+	deferred []deferredElement
+}
+
+func (e *msbuildEvaluator) importFile(path string) error {
+	if e.visited[path] {
+		return nil
+	}
+	e.visited[path] = true
+
+	document, err := decodeMSBuildFile(path)
+	if err != nil {
+		return err
+	}
+
+	return e.evaluate(path, document)
+}
+
+// evaluate runs the property pass over a single file, following its imports
+// as they are reached.
+func (e *msbuildEvaluator) evaluate(path string, document msbuildElement) error {
+	e.project.Files = append(e.project.Files, path)
+
+	for _, element := range document.Children {
+		switch element.XMLName.Local {
+		case "ItemGroup", "Target":
+			e.deferred = append(e.deferred, deferredElement{path: path, element: element})
+			continue
+		}
+
+		if !e.condition(path, element.Attr("Condition")) {
+			continue
+		}
+
+		switch element.XMLName.Local {
+		case "PropertyGroup":
+			for _, property := range element.Children {
+				if !e.condition(path, property.Attr("Condition")) {
+					continue
+				}
+
+				e.project.properties[strings.ToLower(property.XMLName.Local)] = strings.TrimSpace(e.expand(path, property.Value))
+			}
+
+		case "Import":
+			err := e.evaluateImport(path, element)
+			if err != nil {
+				return err
+			}
+
+		case "ImportGroup":
+			for _, imp := range element.Children {
+				if imp.XMLName.Local != "Import" || !e.condition(path, imp.Attr("Condition")) {
+					continue
+				}
+
+				err := e.evaluateImport(path, imp)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (e *msbuildEvaluator) evaluateImport(path string, element msbuildElement) error {
+	// SDK imports resolve to files that ship with the .NET SDK.
+	if element.Attr("Sdk") != "" {
+		return nil
+	}
+
+	target := normalizeMSBuildPath(e.expand(path, element.Attr("Project")))
+	if target == "" || strings.Contains(target, "$(") {
+		return nil
+	}
+
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(path), target)
+	}
+
+	matches, err := filepath.Glob(target)
+	if err != nil {
+		return nil
+	}
+
+	for _, match := range matches {
+		if !withinDir(e.root, match) {
+			continue
+		}
+
+		err = e.importFile(match)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *msbuildEvaluator) evaluateItemsAndTargets(path string, element msbuildElement) {
+	if !e.condition(path, element.Attr("Condition")) {
+		return
+	}
+
+	switch element.XMLName.Local {
+	case "ItemGroup":
+		for _, item := range element.Children {
+			if item.XMLName.Local != "PackageReference" || !e.condition(path, item.Attr("Condition")) {
+				continue
+			}
+
+			e.project.PackageReferences = append(e.project.PackageReferences, splitMSBuildList(e.expand(path, item.Attr("Include")))...)
+
+			for _, name := range splitMSBuildList(e.expand(path, item.Attr("Remove"))) {
+				var references []string
+				for _, reference := range e.project.PackageReferences {
+					if !strings.EqualFold(reference, name) {
+						references = append(references, reference)
+					}
+				}
+				e.project.PackageReferences = references
+			}
+		}
+
+	case "Target":
+		for _, task := range element.Children {
+			if task.XMLName.Local != "Exec" || !e.condition(path, task.Attr("Condition")) {
+				continue
+			}
+
+			e.project.ExecCommands = append(e.project.ExecCommands, e.expand(path, task.Attr("Command")))
+		}
+	}
+}
+
+var msbuildPropertyReference = regexp.MustCompile(`\$\(([A-Za-z_][A-Za-z0-9_.-]*)\)`)
+
+// expand substitutes $(Property) references. References to properties that
+// are not set expand to an empty string, as in MSBuild. Property functions
+// such as $([System.IO.Path]::Combine(...)) are left untouched.
+func (e *msbuildEvaluator) expand(path, value string) string {
+	return msbuildPropertyReference.ReplaceAllStringFunc(value, func(reference string) string {
+		name := strings.ToLower(reference[2 : len(reference)-1])
+		switch name {
+		case "msbuildthisfile":
+			return filepath.Base(path)
+		case "msbuildthisfiledirectory":
+			return filepath.Dir(path) + string(filepath.Separator)
+		case "msbuildthisfilename":
+			return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+
+		return e.project.properties[name]
+	})
+}
+
+// condition evaluates an MSBuild condition in the context of the file at
+// path. Conditions that are empty or that cannot be evaluated are treated as
+// true, so that unknown settings are not silently dropped.
+func (e *msbuildEvaluator) condition(path, condition string) bool {
+	if strings.TrimSpace(condition) == "" {
+		return true
+	}
+
+	condition = e.expand(path, condition)
+	if strings.Contains(condition, "$(") || strings.Contains(condition, "@(") || strings.Contains(condition, "%(") {
+		return true
+	}
+
+	tokens, err := tokenizeCondition(condition)
+	if err != nil {
+		return true
+	}
+
+	parser := conditionParser{tokens: tokens, dir: filepath.Dir(path)}
+	result, err := parser.or()
+	if err != nil || parser.pos != len(parser.tokens) {
+		return true
+	}
+
+	return result
+}
+
+type conditionToken struct {
+	kind  string // "string", "word", "op" or "paren"
+	value string
+}
+
+func tokenizeCondition(condition string) ([]conditionToken, error) {
+	var tokens []conditionToken
+	for i := 0; i < len(condition); {
+		c := condition[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case c == '\'':
+			end := strings.IndexByte(condition[i+1:], '\'')
+			if end < 0 {
+				return nil, errors.New("unterminated string")
+			}
+			tokens = append(tokens, conditionToken{kind: "string", value: condition[i+1 : i+1+end]})
+			i += end + 2
+
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, conditionToken{kind: "paren", value: string(c)})
+			i++
+
+		case strings.HasPrefix(condition[i:], "=="), strings.HasPrefix(condition[i:], "!="):
+			tokens = append(tokens, conditionToken{kind: "op", value: condition[i : i+2]})
+			i += 2
+
+		case c == '!':
+			tokens = append(tokens, conditionToken{kind: "op", value: "!"})
+			i++
+
+		default:
+			start := i
+			for i < len(condition) && strings.IndexByte(" \t\n\r'(),!=<>", condition[i]) < 0 {
+				i++
+			}
+			if start == i {
+				return nil, fmt.Errorf("unsupported character %q", c)
+			}
+			tokens = append(tokens, conditionToken{kind: "word", value: condition[start:i]})
+		}
+	}
+
+	return tokens, nil
+}
+
+type conditionParser struct {
+	tokens []conditionToken
+	pos    int
+	dir    string
+}
+
+func (p *conditionParser) peek() (conditionToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return conditionToken{}, false
+	}
+
+	return p.tokens[p.pos], true
+}
+
+func (p *conditionParser) keyword(word string) bool {
+	token, ok := p.peek()
+	if ok && token.kind == "word" && strings.EqualFold(token.value, word) {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *conditionParser) expect(kind, value string) error {
+	token, ok := p.peek()
+	if !ok || token.kind != kind || token.value != value {
+		return fmt.Errorf("expected %q", value)
+	}
+	p.pos++
+
+	return nil
+}
+
+func (p *conditionParser) or() (bool, error) {
+	result, err := p.and()
+	if err != nil {
+		return false, err
+	}
+
+	for p.keyword("or") {
+		right, err := p.and()
+		if err != nil {
+			return false, err
+		}
+		result = result || right
+	}
+
+	return result, nil
+}
+
+func (p *conditionParser) and() (bool, error) {
+	result, err := p.unary()
+	if err != nil {
+		return false, err
+	}
+
+	for p.keyword("and") {
+		right, err := p.unary()
+		if err != nil {
+			return false, err
+		}
+		result = result && right
+	}
+
+	return result, nil
+}
+
+func (p *conditionParser) unary() (bool, error) {
+	if token, ok := p.peek(); ok && token.kind == "op" && token.value == "!" {
+		p.pos++
+		result, err := p.unary()
+		return !result, err
+	}
+
+	if token, ok := p.peek(); ok && token.kind == "paren" && token.value == "(" {
+		p.pos++
+		result, err := p.or()
+		if err != nil {
+			return false, err
+		}
+
+		return result, p.expect("paren", ")")
+	}
+
+	return p.comparison()
+}
+
+func (p *conditionParser) comparison() (bool, error) {
+	left, isBool, err := p.operand()
+	if err != nil {
+		return false, err
+	}
+
+	token, ok := p.peek()
+	if !ok || token.kind != "op" || token.value == "!" {
+		if isBool {
+			return left == "true", nil
+		}
+
+		switch strings.ToLower(left) {
+		case "true", "on", "yes":
+			return true, nil
+		case "false", "off", "no":
+			return false, nil
+		}
+
+		return false, fmt.Errorf("%q is not a boolean", left)
+	}
+	p.pos++
+
+	right, _, err := p.operand()
+	if err != nil {
+		return false, err
+	}
+
+	equal := strings.EqualFold(left, right)
+	if token.value == "!=" {
+		return !equal, nil
+	}
+
+	return equal, nil
+}
+
+// operand returns the value of a string, word or function call. The second
+// result is true when the value came from a function returning a boolean.
+func (p *conditionParser) operand() (string, bool, error) {
+	token, ok := p.peek()
+	if !ok {
+		return "", false, errors.New("unexpected end of condition")
+	}
+
+	switch token.kind {
+	case "string":
+		p.pos++
+		return token.value, false, nil
+
+	case "word":
+		p.pos++
+		next, ok := p.peek()
+		if !ok || next.kind != "paren" || next.value != "(" {
+			return token.value, false, nil
+		}
+		p.pos++
+
+		argument, _, err := p.operand()
+		if err != nil {
+			return "", false, err
+		}
+
+		err = p.expect("paren", ")")
+		if err != nil {
+			return "", false, err
+		}
+
+		var result bool
+		switch strings.ToLower(token.value) {
+		case "exists":
+			target := normalizeMSBuildPath(argument)
+			if target != "" && !filepath.IsAbs(target) {
+				target = filepath.Join(p.dir, target)
+			}
+			_, err := os.Stat(target)
+			result = target != "" && err == nil
+		case "hastrailingslash":
+			result = strings.HasSuffix(argument, "/") || strings.HasSuffix(argument, `\`)
+		default:
+			return "", false, fmt.Errorf("unsupported function %s", token.value)
+		}
+
+		return fmt.Sprintf("%t", result), true, nil
+	}
+
+	return "", false, fmt.Errorf("unexpected %q", token.value)
+}
+
+// findFileAbove returns the path of the nearest file with the given name in
+// dir or one of its parents, stopping at root so that files outside of the
+// app are never read.
+func findFileAbove(root, dir, name string) string {
+	for withinDir(root, dir) {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	return ""
+}
+
+func normalizeMSBuildPath(path string) string {
+	return filepath.FromSlash(strings.ReplaceAll(strings.TrimSpace(path), `\`, "/"))
+}
+
+func splitMSBuildList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,245 @@
+package dotnetexecute_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	dotnetexecute "github.com/paketo-buildpacks/dotnet-execute"
+	"github.com/sclevine/spec"
+)
+
+func testLoadMSBuildProject(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		repoDir string
+		path    string
+	)
+
+	it.Before(func() {
+		var err error
+		repoDir, err = os.MkdirTemp("", "repo")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(repoDir, "src", "App"), os.ModePerm)).To(Succeed())
+		path = filepath.Join(repoDir, "src", "App", "App.csproj")
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(repoDir)).To(Succeed())
+	})
+
+	it("reads the properties, package references and exec commands of the project", func() {
+		Expect(os.WriteFile(path, []byte(`
+			<Project Sdk="Microsoft.NET.Sdk.Web">
+				<PropertyGroup>
+					<TargetFramework>net8.0</TargetFramework>
+					<SpaRoot>ClientApp\</SpaRoot>
+				</PropertyGroup>
+				<ItemGroup>
+					<PackageReference Include="Serilog;Polly" />
+					<PackageReference Remove="Polly" />
+				</ItemGroup>
+				<Target Name="build-client">
+					<Exec WorkingDirectory="$(SpaRoot)" Command="npm --prefix $(SpaRoot) install" />
+				</Target>
+			</Project>
+		`), 0600)).To(Succeed())
+
+		project, err := dotnetexecute.LoadMSBuildProject(repoDir, path)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(project.Path).To(Equal(path))
+		Expect(project.Files).To(Equal([]string{path}))
+		Expect(project.Property("TargetFramework")).To(Equal("net8.0"))
+		Expect(project.Property("targetframework")).To(Equal("net8.0"))
+		Expect(project.PackageReferences).To(Equal([]string{"Serilog"}))
+		Expect(project.HasPackageReference("serilog")).To(BeTrue())
+		Expect(project.HasPackageReference("Polly")).To(BeFalse())
+		Expect(project.ExecCommands).To(Equal([]string{`npm --prefix ClientApp\ install`}))
+	})
+
+	context("when there are Directory.Build.props and Directory.Build.targets files above the project", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(repoDir, "Directory.Build.props"), []byte(`
+				<Project>
+					<PropertyGroup>
+						<TargetFramework>net6.0</TargetFramework>
+						<ContainerUser>app</ContainerUser>
+					</PropertyGroup>
+				</Project>
+			`), 0600)).To(Succeed())
+
+			Expect(os.WriteFile(filepath.Join(repoDir, "Directory.Build.targets"), []byte(`
+				<Project>
+					<Target Name="build-client" Condition="'$(SpaRoot)' != ''">
+						<Exec Command="yarn --cwd $(SpaRoot) build" />
+					</Target>
+				</Project>
+			`), 0600)).To(Succeed())
+
+			Expect(os.WriteFile(path, []byte(`
+				<Project Sdk="Microsoft.NET.Sdk.Web">
+					<PropertyGroup>
+						<TargetFramework>net8.0</TargetFramework>
+						<SpaRoot>ClientApp</SpaRoot>
+					</PropertyGroup>
+				</Project>
+			`), 0600)).To(Succeed())
+		})
+
+		it("evaluates the props before the project and the targets after it", func() {
+			project, err := dotnetexecute.LoadMSBuildProject(repoDir, path)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(project.Files).To(Equal([]string{
+				filepath.Join(repoDir, "Directory.Build.props"),
+				path,
+				filepath.Join(repoDir, "Directory.Build.targets"),
+			}))
+			Expect(project.Property("TargetFramework")).To(Equal("net8.0"))
+			Expect(project.Property("ContainerUser")).To(Equal("app"))
+			Expect(project.ExecCommands).To(Equal([]string{"yarn --cwd ClientApp build"}))
+		})
+
+		context("when they are above the app root", func() {
+			it("does not read them", func() {
+				project, err := dotnetexecute.LoadMSBuildProject(filepath.Join(repoDir, "src"), path)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(project.Files).To(Equal([]string{path}))
+				Expect(project.Property("ContainerUser")).To(BeEmpty())
+				Expect(project.ExecCommands).To(BeEmpty())
+			})
+		})
+	})
+
+	context("when the project imports other files", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(repoDir, "build"), os.ModePerm)).To(Succeed())
+
+			Expect(os.WriteFile(filepath.Join(repoDir, "build", "shared.props"), []byte(`
+				<Project>
+					<Import Project="$(MSBuildThisFileDirectory)node.targets" />
+					<Import Project="missing.targets" />
+					<Import Project="Sdk.props" Sdk="Microsoft.NET.Sdk" />
+					<PropertyGroup>
+						<SharedImported>true</SharedImported>
+					</PropertyGroup>
+				</Project>
+			`), 0600)).To(Succeed())
+
+			Expect(os.WriteFile(filepath.Join(repoDir, "build", "node.targets"), []byte(`
+				<Project>
+					<Import Project="shared.props" />
+					<Target Name="build-client">
+						<Exec Command="node build.js" />
+					</Target>
+				</Project>
+			`), 0600)).To(Succeed())
+
+			Expect(os.WriteFile(path, []byte(`
+				<Project>
+					<Import Project="..\..\build\shared.props" />
+					<Import Project="..\..\build\skipped.props" Condition="'$(SharedImported)' != 'true'" />
+				</Project>
+			`), 0600)).To(Succeed())
+		})
+
+		it("follows the imports once each", func() {
+			project, err := dotnetexecute.LoadMSBuildProject(repoDir, path)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(project.Files).To(Equal([]string{
+				path,
+				filepath.Join(repoDir, "build", "shared.props"),
+				filepath.Join(repoDir, "build", "node.targets"),
+			}))
+			Expect(project.Property("SharedImported")).To(Equal("true"))
+			Expect(project.ExecCommands).To(Equal([]string{"node build.js"}))
+		})
+
+		context("when they are outside of the app root", func() {
+			it("does not read them", func() {
+				project, err := dotnetexecute.LoadMSBuildProject(filepath.Join(repoDir, "src"), path)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(project.Files).To(Equal([]string{path}))
+				Expect(project.Property("SharedImported")).To(BeEmpty())
+				Expect(project.ExecCommands).To(BeEmpty())
+			})
+		})
+	})
+
+	context("when the project uses conditions", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(repoDir, "src", "App", "package.json"), nil, 0600)).To(Succeed())
+
+			Expect(os.WriteFile(path, []byte(`
+				<Project>
+					<PropertyGroup Condition="'$(Configuration)' == 'Debug'">
+						<Optimize>false</Optimize>
+					</PropertyGroup>
+					<PropertyGroup Condition=" '$(Configuration)|$(Platform)' == 'Release|' ">
+						<Optimize>true</Optimize>
+					</PropertyGroup>
+					<PropertyGroup>
+						<HasPackageJSON Condition="Exists('package.json')">true</HasPackageJSON>
+						<HasYarnLock Condition="Exists('yarn.lock')">true</HasYarnLock>
+						<Both Condition="'$(Optimize)' == 'true' and ('$(HasYarnLock)' == 'true' or !Exists('yarn.lock'))">yes</Both>
+						<Unknown Condition="$([MSBuild]::IsOSPlatform('Windows'))">kept</Unknown>
+					</PropertyGroup>
+					<ItemGroup Condition="'$(Optimize)' != 'true'">
+						<PackageReference Include="Debug.Only" />
+					</ItemGroup>
+				</Project>
+			`), 0600)).To(Succeed())
+		})
+
+		it("evaluates them", func() {
+			project, err := dotnetexecute.LoadMSBuildProject(repoDir, path)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(project.Property("Optimize")).To(Equal("true"))
+			Expect(project.Property("HasPackageJSON")).To(Equal("true"))
+			Expect(project.Property("HasYarnLock")).To(Equal(""))
+			Expect(project.Property("Both")).To(Equal("yes"))
+			Expect(project.Property("Unknown")).To(Equal("kept"))
+			Expect(project.PackageReferences).To(BeEmpty())
+		})
+	})
+
+	context("failure cases", func() {
+		context("when the project file can not be opened", func() {
+			it("returns an error", func() {
+				_, err := dotnetexecute.LoadMSBuildProject(repoDir, path)
+				Expect(err).To(MatchError(ContainSubstring("failed to open")))
+			})
+		})
+
+		context("when the project file can not be decoded", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte("%%%"), 0600)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := dotnetexecute.LoadMSBuildProject(repoDir, path)
+				Expect(err).To(MatchError(ContainSubstring("failed to decode")))
+			})
+		})
+
+		context("when an imported file can not be decoded", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(repoDir, "Directory.Build.props"), []byte("%%%"), 0600)).To(Succeed())
+				Expect(os.WriteFile(path, []byte("<Project />"), 0600)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := dotnetexecute.LoadMSBuildProject(repoDir, path)
+				Expect(err).To(MatchError(ContainSubstring(filepath.Join(repoDir, "Directory.Build.props"))))
+			})
+		})
+	})
+}
@@ -0,0 +1,153 @@
+package dotnetexecute
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+)
+
+// NativeLibrary is a system library that the app loads at runtime, along with
+// the packages or native assets that need it.
+type NativeLibrary struct {
+	Name    string
+	Sources []string
+}
+
+// nativeLibraryPackages maps NuGet packages to the system libraries they load.
+var nativeLibraryPackages = []struct {
+	pkg       string
+	libraries []string
+}{
+	{"System.Drawing.Common", []string{"libgdiplus", "libfontconfig"}},
+	{"SkiaSharp", []string{"libfontconfig"}},
+	{"Microsoft.Data.SqlClient", []string{"libgssapi_krb5"}},
+	{"System.Data.SqlClient", []string{"libgssapi_krb5"}},
+	{"Npgsql", []string{"libgssapi_krb5"}},
+	{"TimeZoneConverter", []string{"tzdata"}},
+}
+
+// nativeLibraryAssets maps the native assets that packages ship to the system
+// libraries that those assets link against.
+var nativeLibraryAssets = []struct {
+	asset     string
+	libraries []string
+}{
+	{"libSkiaSharp.so", []string{"libfontconfig"}},
+}
+
+// FindNativeLibraries returns the system libraries needed by the packages
+// and native assets listed in the deps.json file at depsPath, and by the
+// given package references. A missing deps.json file is not an error.
+func FindNativeLibraries(depsPath string, packageReferences []string) ([]NativeLibrary, error) {
+	packages := packageReferences
+	var assets []string
+
+	if depsPath != "" {
+		depsPackages, depsAssets, err := readDepsJSON(depsPath)
+		if err != nil {
+			return nil, err
+		}
+
+		packages = append(append([]string{}, packages...), depsPackages...)
+		assets = depsAssets
+	}
+
+	var libraries []NativeLibrary
+	add := func(names []string, source string) {
+		for _, name := range names {
+			index := slices.IndexFunc(libraries, func(library NativeLibrary) bool {
+				return library.Name == name
+			})
+
+			if index < 0 {
+				libraries = append(libraries, NativeLibrary{Name: name})
+				index = len(libraries) - 1
+			}
+
+			if !slices.Contains(libraries[index].Sources, source) {
+				libraries[index].Sources = append(libraries[index].Sources, source)
+			}
+		}
+	}
+
+	for _, pkg := range packages {
+		for _, known := range nativeLibraryPackages {
+			if strings.EqualFold(pkg, known.pkg) {
+				add(known.libraries, fmt.Sprintf("package %s", known.pkg))
+			}
+		}
+	}
+
+	for _, asset := range assets {
+		for _, known := range nativeLibraryAssets {
+			if strings.EqualFold(path.Base(asset), known.asset) {
+				add(known.libraries, fmt.Sprintf("native asset %s", known.asset))
+			}
+		}
+	}
+
+	return libraries, nil
+}
+
+// readDepsJSON returns the names of the packages in a deps.json file and the
+// paths of the native assets that they ship.
+func readDepsJSON(path string) ([]string, []string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	var deps struct {
+		Targets map[string]map[string]struct {
+			Native         map[string]json.RawMessage `json:"native"`
+			RuntimeTargets map[string]struct {
+				AssetType string `json:"assetType"`
+			} `json:"runtimeTargets"`
+		} `json:"targets"`
+		Libraries map[string]struct {
+			Type string `json:"type"`
+		} `json:"libraries"`
+	}
+
+	err = json.Unmarshal(content, &deps)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	var packages []string
+	for id, library := range deps.Libraries {
+		if library.Type != "package" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(id, "/")
+		packages = append(packages, name)
+	}
+
+	var assets []string
+	for _, target := range deps.Targets {
+		for _, library := range target {
+			for asset := range library.Native {
+				assets = append(assets, asset)
+			}
+
+			for asset, runtimeTarget := range library.RuntimeTargets {
+				if runtimeTarget.AssetType == "native" {
+					assets = append(assets, asset)
+				}
+			}
+		}
+	}
+
+	slices.Sort(packages)
+	slices.Sort(assets)
+
+	return packages, assets, nil
+}
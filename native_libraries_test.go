@@ -0,0 +1,93 @@
+package dotnetexecute_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	dotnetexecute "github.com/paketo-buildpacks/dotnet-execute"
+	"github.com/sclevine/spec"
+)
+
+func testFindNativeLibraries(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		workingDir string
+		depsPath   string
+	)
+
+	it.Before(func() {
+		var err error
+		workingDir, err = os.MkdirTemp("", "working-dir")
+		Expect(err).NotTo(HaveOccurred())
+
+		depsPath = filepath.Join(workingDir, "app.deps.json")
+		Expect(os.WriteFile(depsPath, []byte(`{
+			"targets": {
+				".NETCoreApp,Version=v8.0": {
+					"SkiaSharp.NativeAssets.Linux/2.88.7": {
+						"runtimeTargets": {
+							"runtimes/linux-x64/native/libSkiaSharp.so": {
+								"rid": "linux-x64",
+								"assetType": "native"
+							}
+						}
+					}
+				}
+			},
+			"libraries": {
+				"app/1.0.0": { "type": "project" },
+				"System.Drawing.Common/8.0.0": { "type": "package" },
+				"SkiaSharp.NativeAssets.Linux/2.88.7": { "type": "package" }
+			}
+		}`), 0600)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(workingDir)).To(Succeed())
+	})
+
+	it("maps packages and native assets to system libraries", func() {
+		libraries, err := dotnetexecute.FindNativeLibraries(depsPath, []string{"npgsql", "TimeZoneConverter"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(libraries).To(Equal([]dotnetexecute.NativeLibrary{
+			{Name: "libgssapi_krb5", Sources: []string{"package Npgsql"}},
+			{Name: "tzdata", Sources: []string{"package TimeZoneConverter"}},
+			{Name: "libgdiplus", Sources: []string{"package System.Drawing.Common"}},
+			{Name: "libfontconfig", Sources: []string{"package System.Drawing.Common", "native asset libSkiaSharp.so"}},
+		}))
+	})
+
+	context("when there is no deps.json", func() {
+		it("only looks at the package references", func() {
+			libraries, err := dotnetexecute.FindNativeLibraries(filepath.Join(workingDir, "missing.deps.json"), []string{"Microsoft.Data.SqlClient"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(libraries).To(Equal([]dotnetexecute.NativeLibrary{
+				{Name: "libgssapi_krb5", Sources: []string{"package Microsoft.Data.SqlClient"}},
+			}))
+		})
+	})
+
+	context("when nothing needs a system library", func() {
+		it("returns nothing", func() {
+			libraries, err := dotnetexecute.FindNativeLibraries("", []string{"Serilog"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(libraries).To(BeEmpty())
+		})
+	})
+
+	context("failure cases", func() {
+		context("when the deps.json can not be decoded", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(depsPath, []byte("%%%"), 0600)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := dotnetexecute.FindNativeLibraries(depsPath, nil)
+				Expect(err).To(MatchError(ContainSubstring("failed to decode")))
+			})
+		})
+	})
+}
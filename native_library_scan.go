@@ -0,0 +1,168 @@
+package dotnetexecute
+
+import (
+	"debug/elf"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// DefaultLibraryPaths are the directories that the dynamic linker searches
+// for shared libraries on the distributions that the buildpack supports.
+var DefaultLibraryPaths = []string{
+	"/lib",
+	"/lib64",
+	"/usr/lib",
+	"/usr/lib64",
+	"/usr/local/lib",
+	"/lib/x86_64-linux-gnu",
+	"/usr/lib/x86_64-linux-gnu",
+	"/lib/aarch64-linux-gnu",
+	"/usr/lib/aarch64-linux-gnu",
+}
+
+// UnresolvedLibrary is a shared library that native libraries in the app
+// link against, but that could not be found.
+type UnresolvedLibrary struct {
+	Name     string
+	NeededBy []string
+}
+
+// FindUnresolvedLibraries reads the DT_NEEDED entries of every ELF shared
+// library under appDir and returns those that are neither shipped in appDir
+// nor present in one of searchPaths. The runtimes/<rid> directories of a
+// portable publish are only read for the RIDs that the host loads assets
+// from, as the others hold libraries for other platforms, such as musl.
+// NeededBy paths are relative to appDir.
+func FindUnresolvedLibraries(appDir string, searchPaths []string) ([]UnresolvedLibrary, error) {
+	var (
+		libraries []string
+		shipped   = map[string]bool{}
+		rids      = compatibleRIDs(runtime.GOARCH)
+	)
+
+	err := filepath.WalkDir(appDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			if filepath.Base(filepath.Dir(path)) == "runtimes" && !slices.Contains(rids, entry.Name()) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if !isSharedLibraryName(entry.Name()) {
+			return nil
+		}
+
+		// A symlink such as libfoo.so -> libfoo.so.1 still makes its name
+		// available to the linker, but only the target needs to be read.
+		shipped[entry.Name()] = true
+		if entry.Type().IsRegular() {
+			libraries = append(libraries, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var unresolved []UnresolvedLibrary
+	for _, library := range libraries {
+		needed, err := neededLibraries(library)
+		if err != nil {
+			return nil, err
+		}
+
+		rel, err := filepath.Rel(appDir, library)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range needed {
+			if shipped[name] || inLibraryPaths(name, searchPaths) {
+				continue
+			}
+
+			index := slices.IndexFunc(unresolved, func(u UnresolvedLibrary) bool {
+				return u.Name == name
+			})
+
+			if index < 0 {
+				unresolved = append(unresolved, UnresolvedLibrary{Name: name})
+				index = len(unresolved) - 1
+			}
+			unresolved[index].NeededBy = append(unresolved[index].NeededBy, rel)
+		}
+	}
+
+	return unresolved, nil
+}
+
+// neededLibraries returns the DT_NEEDED entries of the ELF file at path. Files
+// that are not ELF files have none.
+func neededLibraries(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	magic := make([]byte, len(elf.ELFMAG))
+	_, err = io.ReadFull(file, magic)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if string(magic) != elf.ELFMAG {
+		return nil, nil
+	}
+
+	elfFile, err := elf.NewFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	needed, err := elfFile.ImportedLibraries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the dynamic section of %s: %w", path, err)
+	}
+
+	return needed, nil
+}
+
+// compatibleRIDs returns the runtime identifiers whose native assets the .NET
+// host loads on glibc Linux for the Go architecture goarch.
+func compatibleRIDs(goarch string) []string {
+	arch := goarch
+	if goarch == "amd64" {
+		arch = "x64"
+	}
+
+	return []string{fmt.Sprintf("linux-%s", arch), "linux", "unix"}
+}
+
+func isSharedLibraryName(name string) bool {
+	return strings.HasSuffix(name, ".so") || strings.Contains(name, ".so.")
+}
+
+func inLibraryPaths(name string, searchPaths []string) bool {
+	for _, dir := range searchPaths {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,157 @@
+package dotnetexecute_test
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	dotnetexecute "github.com/paketo-buildpacks/dotnet-execute"
+	"github.com/sclevine/spec"
+)
+
+// writeSharedLibrary writes a minimal 64-bit ELF shared object whose dynamic
+// section lists the given DT_NEEDED entries.
+func writeSharedLibrary(path string, needed ...string) error {
+	dynstr := []byte{0}
+	var dynamic []elf.Dyn64
+	for _, name := range needed {
+		dynamic = append(dynamic, elf.Dyn64{Tag: int64(elf.DT_NEEDED), Val: uint64(len(dynstr))})
+		dynstr = append(append(dynstr, name...), 0)
+	}
+	dynamic = append(dynamic, elf.Dyn64{Tag: int64(elf.DT_NULL)})
+
+	const headerSize, dynSize, sectionSize = 64, 16, 64
+	dynstrOffset := uint64(headerSize)
+	dynamicOffset := dynstrOffset + uint64(len(dynstr))
+	sectionsOffset := dynamicOffset + uint64(len(dynamic)*dynSize)
+
+	header := elf.Header64{
+		Type:      uint16(elf.ET_DYN),
+		Machine:   uint16(elf.EM_X86_64),
+		Version:   uint32(elf.EV_CURRENT),
+		Shoff:     sectionsOffset,
+		Ehsize:    headerSize,
+		Shentsize: sectionSize,
+		Shnum:     3,
+	}
+	copy(header.Ident[:], elf.ELFMAG)
+	header.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	header.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	header.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+
+	sections := []elf.Section64{
+		{},
+		{Type: uint32(elf.SHT_STRTAB), Off: dynstrOffset, Size: uint64(len(dynstr)), Addralign: 1},
+		{Type: uint32(elf.SHT_DYNAMIC), Off: dynamicOffset, Size: uint64(len(dynamic) * dynSize), Link: 1, Addralign: 8, Entsize: dynSize},
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	for _, data := range []any{header, dynstr, dynamic, sections} {
+		err := binary.Write(buffer, binary.LittleEndian, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return os.WriteFile(path, buffer.Bytes(), 0755)
+}
+
+func testFindUnresolvedLibraries(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		appDir string
+		libDir string
+		rid    string
+	)
+
+	it.Before(func() {
+		var err error
+		appDir, err = os.MkdirTemp("", "app")
+		Expect(err).NotTo(HaveOccurred())
+
+		libDir, err = os.MkdirTemp("", "lib")
+		Expect(err).NotTo(HaveOccurred())
+
+		rid = "linux-" + runtime.GOARCH
+		if runtime.GOARCH == "amd64" {
+			rid = "linux-x64"
+		}
+
+		Expect(os.MkdirAll(filepath.Join(appDir, "runtimes", rid, "native"), os.ModePerm)).To(Succeed())
+
+		Expect(writeSharedLibrary(filepath.Join(appDir, "libcoreclr.so"), "libclrjit.so", "libc.so.6")).To(Succeed())
+		Expect(writeSharedLibrary(filepath.Join(appDir, "libclrjit.so"), "libc.so.6")).To(Succeed())
+		Expect(writeSharedLibrary(filepath.Join(appDir, "runtimes", rid, "native", "libSkiaSharp.so"), "libfontconfig.so.1", "libc.so.6")).To(Succeed())
+		Expect(writeSharedLibrary(filepath.Join(appDir, "libgssapi.so"), "libgssapi_krb5.so.2", "libfontconfig.so.1")).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(appDir, "not-elf.so"), []byte("%%%"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(appDir, "app.dll"), nil, 0644)).To(Succeed())
+
+		Expect(os.WriteFile(filepath.Join(libDir, "libc.so.6"), nil, 0644)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(appDir)).To(Succeed())
+		Expect(os.RemoveAll(libDir)).To(Succeed())
+	})
+
+	it("returns the needed libraries that are not in the app or the search paths", func() {
+		unresolved, err := dotnetexecute.FindUnresolvedLibraries(appDir, []string{"/does/not/exist", libDir})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unresolved).To(Equal([]dotnetexecute.UnresolvedLibrary{
+			{Name: "libgssapi_krb5.so.2", NeededBy: []string{"libgssapi.so"}},
+			{Name: "libfontconfig.so.1", NeededBy: []string{"libgssapi.so", filepath.Join("runtimes", rid, "native", "libSkiaSharp.so")}},
+		}))
+	})
+
+	context("when the app ships native assets for other runtime identifiers", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(appDir, "runtimes", "linux-musl-x64", "native"), os.ModePerm)).To(Succeed())
+			Expect(writeSharedLibrary(filepath.Join(appDir, "runtimes", "linux-musl-x64", "native", "libSkiaSharp.so"), "libc.musl-x86_64.so.1")).To(Succeed())
+
+			Expect(os.MkdirAll(filepath.Join(appDir, "runtimes", "linux-arm", "native"), os.ModePerm)).To(Succeed())
+			Expect(writeSharedLibrary(filepath.Join(appDir, "runtimes", "linux-arm", "native", "libSkiaSharp.so"), "libarm-only.so")).To(Succeed())
+
+			Expect(os.WriteFile(filepath.Join(libDir, "libfontconfig.so.1"), nil, 0644)).To(Succeed())
+			Expect(os.Symlink(filepath.Join(appDir, "libgssapi.so"), filepath.Join(appDir, "libgssapi_krb5.so.2"))).To(Succeed())
+		})
+
+		it("does not read them", func() {
+			unresolved, err := dotnetexecute.FindUnresolvedLibraries(appDir, []string{libDir})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unresolved).To(BeEmpty())
+		})
+	})
+
+	context("when every needed library is found", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(libDir, "libfontconfig.so.1"), nil, 0644)).To(Succeed())
+			Expect(os.Symlink(filepath.Join(appDir, "libgssapi.so"), filepath.Join(appDir, "libgssapi_krb5.so.2"))).To(Succeed())
+		})
+
+		it("returns nothing", func() {
+			unresolved, err := dotnetexecute.FindUnresolvedLibraries(appDir, []string{libDir})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unresolved).To(BeEmpty())
+		})
+	})
+
+	context("failure cases", func() {
+		context("when a library is a truncated ELF file", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(appDir, "libbroken.so"), []byte(elf.ELFMAG+"\x02\x01"), 0644)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := dotnetexecute.FindUnresolvedLibraries(appDir, []string{libDir})
+				Expect(err).To(MatchError(ContainSubstring("failed to read")))
+				Expect(err).To(MatchError(ContainSubstring("libbroken.so")))
+			})
+		})
+	})
+}
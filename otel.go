@@ -0,0 +1,68 @@
+package dotnetexecute
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// OTelProfilerID is the CLSID of the OpenTelemetry .NET CLR profiler.
+const OTelProfilerID = "{918728DD-259F-4A6A-AC2B-B85E1B658318}"
+
+// OTelAutoInstrumentation is an install of OpenTelemetry .NET
+// automatic instrumentation, as unpacked from its release archive.
+type OTelAutoInstrumentation struct {
+	Home           string
+	ProfilerPath   string
+	StartupHook    string
+	AdditionalDeps string
+	SharedStore    string
+}
+
+// FindOTelAutoInstrumentation checks that home holds the files that the
+// OpenTelemetry .NET automatic instrumentation loads into the app. The native
+// profiler is picked for goarch, preferring the glibc build over the musl
+// one.
+func FindOTelAutoInstrumentation(home, goarch string) (OTelAutoInstrumentation, error) {
+	var arch string
+	switch goarch {
+	case "amd64":
+		arch = "x64"
+	case "arm64":
+		arch = "arm64"
+	default:
+		return OTelAutoInstrumentation{}, fmt.Errorf("OpenTelemetry .NET automatic instrumentation does not support %s", goarch)
+	}
+
+	instrumentation := OTelAutoInstrumentation{
+		Home:           home,
+		StartupHook:    filepath.Join(home, "net", "OpenTelemetry.AutoInstrumentation.StartupHook.dll"),
+		AdditionalDeps: filepath.Join(home, "AdditionalDeps"),
+		SharedStore:    filepath.Join(home, "store"),
+	}
+
+	for _, platform := range []string{"linux-" + arch, "linux-musl-" + arch} {
+		path := filepath.Join(home, platform, "OpenTelemetry.AutoInstrumentation.Native.so")
+		if _, err := os.Stat(path); err == nil {
+			instrumentation.ProfilerPath = path
+			break
+		}
+	}
+
+	if instrumentation.ProfilerPath == "" {
+		return OTelAutoInstrumentation{}, fmt.Errorf("OpenTelemetry .NET automatic instrumentation in %s has no native profiler for linux-%s", home, arch)
+	}
+
+	for _, path := range []string{instrumentation.StartupHook, instrumentation.AdditionalDeps, instrumentation.SharedStore} {
+		_, err := os.Stat(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return OTelAutoInstrumentation{}, fmt.Errorf("OpenTelemetry .NET automatic instrumentation in %s is incomplete: %s is missing", home, filepath.Base(path))
+			}
+			return OTelAutoInstrumentation{}, err
+		}
+	}
+
+	return instrumentation, nil
+}
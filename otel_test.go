@@ -0,0 +1,101 @@
+package dotnetexecute_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	dotnetexecute "github.com/paketo-buildpacks/dotnet-execute"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+// writeOTelAutoInstrumentation lays out the files of an OpenTelemetry .NET
+// automatic instrumentation release for the given platform.
+func writeOTelAutoInstrumentation(home, platform string) error {
+	for _, dir := range []string{platform, "net", "AdditionalDeps", "store"} {
+		err := os.MkdirAll(filepath.Join(home, dir), os.ModePerm)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := os.WriteFile(filepath.Join(home, platform, "OpenTelemetry.AutoInstrumentation.Native.so"), nil, 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(home, "net", "OpenTelemetry.AutoInstrumentation.StartupHook.dll"), nil, 0644)
+}
+
+func testFindOTelAutoInstrumentation(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		home string
+	)
+
+	it.Before(func() {
+		var err error
+		home, err = os.MkdirTemp("", "otel")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(writeOTelAutoInstrumentation(home, "linux-x64")).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(home)).To(Succeed())
+	})
+
+	it("returns the paths of the instrumentation files", func() {
+		instrumentation, err := dotnetexecute.FindOTelAutoInstrumentation(home, "amd64")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(instrumentation).To(Equal(dotnetexecute.OTelAutoInstrumentation{
+			Home:           home,
+			ProfilerPath:   filepath.Join(home, "linux-x64", "OpenTelemetry.AutoInstrumentation.Native.so"),
+			StartupHook:    filepath.Join(home, "net", "OpenTelemetry.AutoInstrumentation.StartupHook.dll"),
+			AdditionalDeps: filepath.Join(home, "AdditionalDeps"),
+			SharedStore:    filepath.Join(home, "store"),
+		}))
+	})
+
+	context("when only the musl profiler is present", func() {
+		it.Before(func() {
+			Expect(writeOTelAutoInstrumentation(home, "linux-musl-arm64")).To(Succeed())
+		})
+
+		it("uses it", func() {
+			instrumentation, err := dotnetexecute.FindOTelAutoInstrumentation(home, "arm64")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instrumentation.ProfilerPath).To(Equal(filepath.Join(home, "linux-musl-arm64", "OpenTelemetry.AutoInstrumentation.Native.so")))
+		})
+	})
+
+	context("failure cases", func() {
+		context("when the architecture is not supported", func() {
+			it("returns an error", func() {
+				_, err := dotnetexecute.FindOTelAutoInstrumentation(home, "s390x")
+				Expect(err).To(MatchError("OpenTelemetry .NET automatic instrumentation does not support s390x"))
+			})
+		})
+
+		context("when there is no profiler for the architecture", func() {
+			it("returns an error", func() {
+				_, err := dotnetexecute.FindOTelAutoInstrumentation(home, "arm64")
+				Expect(err).To(MatchError(ContainSubstring("has no native profiler for linux-arm64")))
+			})
+		})
+
+		context("when the startup hook is missing", func() {
+			it.Before(func() {
+				Expect(os.RemoveAll(filepath.Join(home, "net"))).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := dotnetexecute.FindOTelAutoInstrumentation(home, "amd64")
+				Expect(err).To(MatchError(ContainSubstring("is incomplete: OpenTelemetry.AutoInstrumentation.StartupHook.dll is missing")))
+			})
+		})
+	})
+}
@@ -0,0 +1,167 @@
+package dotnetexecute
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/paketo-buildpacks/packit/v2"
+)
+
+// ProcessDescriptorFile is the name of the file, in the app root, that
+// declares additional process types.
+const ProcessDescriptorFile = "dotnet-processes.toml"
+
+// ProcessDefinition declares an additional launch process. Command is
+// relative to the app root and may not leave it; a command ending in .dll is
+// run with dotnet, or with its apphost when the app has no shared runtime.
+type ProcessDefinition struct {
+	Type    string   `toml:"type"`
+	Command string   `toml:"command"`
+	Args    []string `toml:"args"`
+	Default bool     `toml:"default"`
+}
+
+type ProcessDescriptorParser struct{}
+
+func NewProcessDescriptorParser() ProcessDescriptorParser {
+	return ProcessDescriptorParser{}
+}
+
+// Parse reads the process definitions from a dotnet-processes.toml file. A
+// missing file declares no processes.
+func (p ProcessDescriptorParser) Parse(path string) ([]ProcessDefinition, error) {
+	var descriptor struct {
+		Processes []ProcessDefinition `toml:"processes"`
+	}
+
+	_, err := toml.DecodeFile(path, &descriptor)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	return descriptor.Processes, nil
+}
+
+// ParseProcessDefinitions reads process definitions from the
+// BP_DOTNET_PROCESSES format: a semicolon-separated list of
+// <type>=<command> [args...] entries, where the command and its arguments
+// follow shell quoting rules. A type ending in * marks the default process,
+// as in worker*=MyApp.dll worker.
+func ParseProcessDefinitions(value string) ([]ProcessDefinition, error) {
+	var definitions []ProcessDefinition
+	for _, entry := range strings.Split(value, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		processType, commandLine, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("malformed process definition %q: expected <type>=<command> [args...]", entry)
+		}
+
+		words, err := ParseArgs(commandLine)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(words) == 0 {
+			return nil, fmt.Errorf("malformed process definition %q: missing command", entry)
+		}
+
+		processType, isDefault := strings.CutSuffix(strings.TrimSpace(processType), "*")
+
+		definitions = append(definitions, ProcessDefinition{
+			Type:    strings.TrimSpace(processType),
+			Command: words[0],
+			Args:    words[1:],
+			Default: isDefault,
+		})
+	}
+
+	return definitions, nil
+}
+
+// Process turns the definition into a launch process, checking that its
+// command exists under root. The kind of the app decides whether a DLL can
+// be run with dotnet, which is only on the launch image of apps that use a
+// shared runtime.
+func (d ProcessDefinition) Process(root string, kind AppKind) (packit.Process, error) {
+	if d.Type == "" {
+		return packit.Process{}, fmt.Errorf("process definition for command %q is missing a type", d.Command)
+	}
+
+	if d.Command == "" {
+		return packit.Process{}, fmt.Errorf("process definition %q is missing a command", d.Type)
+	}
+
+	path := filepath.Join(root, d.Command)
+	if !withinDir(root, path) {
+		return packit.Process{}, fmt.Errorf("command for process %q is outside of the app root: %s", d.Type, d.Command)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return packit.Process{}, fmt.Errorf("command for process %q not found: %w", d.Type, err)
+		}
+		return packit.Process{}, err
+	}
+
+	// A symlink in the app root can still point elsewhere.
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return packit.Process{}, err
+	}
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return packit.Process{}, err
+	}
+
+	if !withinDir(resolvedRoot, resolved) {
+		return packit.Process{}, fmt.Errorf("command for process %q resolves outside of the app root: %s", d.Type, d.Command)
+	}
+
+	if strings.HasSuffix(d.Command, ".dll") {
+		if kind == AppKindFrameworkDependentDeployment || kind == AppKindFrameworkDependentExecutable || kind == AppKindSource {
+			return packit.Process{
+				Type:    d.Type,
+				Command: "dotnet",
+				Args:    append([]string{path}, d.Args...),
+				Default: d.Default,
+				Direct:  true,
+			}, nil
+		}
+
+		apphost := strings.TrimSuffix(path, ".dll")
+		apphostInfo, err := os.Stat(apphost)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return packit.Process{}, err
+		}
+
+		if err != nil || apphostInfo.IsDir() || apphostInfo.Mode()&0111 == 0 {
+			return packit.Process{}, fmt.Errorf("process %q runs %s, but the %s app has no dotnet to run it with and there is no apphost %s", d.Type, d.Command, kind, strings.TrimSuffix(d.Command, ".dll"))
+		}
+
+		path, info = apphost, apphostInfo
+	}
+
+	if info.IsDir() || info.Mode()&0111 == 0 {
+		return packit.Process{}, fmt.Errorf("command for process %q is not executable: %s", d.Type, path)
+	}
+
+	return packit.Process{
+		Type:    d.Type,
+		Command: path,
+		Args:    d.Args,
+		Default: d.Default,
+		Direct:  true,
+	}, nil
+}
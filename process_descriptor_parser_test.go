@@ -0,0 +1,191 @@
+package dotnetexecute_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	dotnetexecute "github.com/paketo-buildpacks/dotnet-execute"
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/sclevine/spec"
+)
+
+func testProcessDescriptorParser(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		workingDir string
+		parser     dotnetexecute.ProcessDescriptorParser
+	)
+
+	it.Before(func() {
+		var err error
+		workingDir, err = os.MkdirTemp("", "working-dir")
+		Expect(err).NotTo(HaveOccurred())
+
+		parser = dotnetexecute.NewProcessDescriptorParser()
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(workingDir)).To(Succeed())
+	})
+
+	context("Parse", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "dotnet-processes.toml"), []byte(`
+[[processes]]
+  type = "migrate"
+  command = "efbundle"
+  args = ["--verbose"]
+
+[[processes]]
+  type = "worker"
+  command = "my.app.dll"
+  args = ["worker"]
+  default = true
+`), 0600)).To(Succeed())
+		})
+
+		it("returns the process definitions", func() {
+			definitions, err := parser.Parse(filepath.Join(workingDir, "dotnet-processes.toml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(definitions).To(Equal([]dotnetexecute.ProcessDefinition{
+				{Type: "migrate", Command: "efbundle", Args: []string{"--verbose"}},
+				{Type: "worker", Command: "my.app.dll", Args: []string{"worker"}, Default: true},
+			}))
+		})
+
+		context("when the file does not exist", func() {
+			it("returns no definitions", func() {
+				definitions, err := parser.Parse(filepath.Join(workingDir, "missing.toml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(definitions).To(BeEmpty())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the file cannot be decoded", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(workingDir, "dotnet-processes.toml"), []byte("%%%"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := parser.Parse(filepath.Join(workingDir, "dotnet-processes.toml"))
+					Expect(err).To(MatchError(ContainSubstring("failed to decode")))
+				})
+			})
+		})
+	})
+
+	context("ParseProcessDefinitions", func() {
+		it("returns the process definitions", func() {
+			definitions, err := dotnetexecute.ParseProcessDefinitions(`migrate=efbundle --connection "Host=db"; seed = my.app.dll seed ; worker* = my.app.dll worker`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(definitions).To(Equal([]dotnetexecute.ProcessDefinition{
+				{Type: "migrate", Command: "efbundle", Args: []string{"--connection", "Host=db"}},
+				{Type: "seed", Command: "my.app.dll", Args: []string{"seed"}},
+				{Type: "worker", Command: "my.app.dll", Args: []string{"worker"}, Default: true},
+			}))
+		})
+
+		context("failure cases", func() {
+			it("errors when an entry has no type", func() {
+				_, err := dotnetexecute.ParseProcessDefinitions("efbundle")
+				Expect(err).To(MatchError(ContainSubstring("expected <type>=<command> [args...]")))
+			})
+
+			it("errors when an entry has no command", func() {
+				_, err := dotnetexecute.ParseProcessDefinitions("migrate= ")
+				Expect(err).To(MatchError(ContainSubstring("missing command")))
+			})
+		})
+	})
+
+	context("ProcessDefinition.Process", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "efbundle"), nil, 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "my.app.dll"), nil, 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "data.txt"), nil, 0644)).To(Succeed())
+		})
+
+		it("runs executables directly", func() {
+			process, err := dotnetexecute.ProcessDefinition{Type: "migrate", Command: "efbundle", Args: []string{"--verbose"}}.Process(workingDir, dotnetexecute.AppKindFrameworkDependentDeployment)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(process).To(Equal(packit.Process{
+				Type:    "migrate",
+				Command: filepath.Join(workingDir, "efbundle"),
+				Args:    []string{"--verbose"},
+				Direct:  true,
+			}))
+		})
+
+		it("runs DLLs with dotnet", func() {
+			process, err := dotnetexecute.ProcessDefinition{Type: "seed", Command: "my.app.dll", Args: []string{"seed"}, Default: true}.Process(workingDir, dotnetexecute.AppKindFrameworkDependentDeployment)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(process).To(Equal(packit.Process{
+				Type:    "seed",
+				Command: "dotnet",
+				Args:    []string{filepath.Join(workingDir, "my.app.dll"), "seed"},
+				Default: true,
+				Direct:  true,
+			}))
+		})
+
+		context("when the app has no shared runtime", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "my.app"), nil, 0755)).To(Succeed())
+			})
+
+			it("runs DLLs with their apphost", func() {
+				process, err := dotnetexecute.ProcessDefinition{Type: "seed", Command: "my.app.dll", Args: []string{"seed"}}.Process(workingDir, dotnetexecute.AppKindSelfContained)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(process).To(Equal(packit.Process{
+					Type:    "seed",
+					Command: filepath.Join(workingDir, "my.app"),
+					Args:    []string{"seed"},
+					Direct:  true,
+				}))
+			})
+		})
+
+		context("failure cases", func() {
+			it("errors when the type is missing", func() {
+				_, err := dotnetexecute.ProcessDefinition{Command: "efbundle"}.Process(workingDir, dotnetexecute.AppKindFrameworkDependentDeployment)
+				Expect(err).To(MatchError(`process definition for command "efbundle" is missing a type`))
+			})
+
+			it("errors when the command is missing", func() {
+				_, err := dotnetexecute.ProcessDefinition{Type: "migrate"}.Process(workingDir, dotnetexecute.AppKindFrameworkDependentDeployment)
+				Expect(err).To(MatchError(`process definition "migrate" is missing a command`))
+			})
+
+			it("errors when the command does not exist", func() {
+				_, err := dotnetexecute.ProcessDefinition{Type: "migrate", Command: "missing"}.Process(workingDir, dotnetexecute.AppKindFrameworkDependentDeployment)
+				Expect(err).To(MatchError(ContainSubstring(`command for process "migrate" not found`)))
+			})
+
+			it("errors when the command leaves the app root", func() {
+				_, err := dotnetexecute.ProcessDefinition{Type: "shell", Command: "../../bin/sh"}.Process(workingDir, dotnetexecute.AppKindFrameworkDependentDeployment)
+				Expect(err).To(MatchError(`command for process "shell" is outside of the app root: ../../bin/sh`))
+			})
+
+			it("errors when the command is a symlink out of the app root", func() {
+				Expect(os.Symlink("/bin/sh", filepath.Join(workingDir, "sh"))).To(Succeed())
+
+				_, err := dotnetexecute.ProcessDefinition{Type: "shell", Command: "sh"}.Process(workingDir, dotnetexecute.AppKindFrameworkDependentDeployment)
+				Expect(err).To(MatchError(`command for process "shell" resolves outside of the app root: sh`))
+			})
+
+			it("errors when a self-contained app has no apphost for a DLL", func() {
+				_, err := dotnetexecute.ProcessDefinition{Type: "seed", Command: "my.app.dll"}.Process(workingDir, dotnetexecute.AppKindSelfContained)
+				Expect(err).To(MatchError(`process "seed" runs my.app.dll, but the self-contained app has no dotnet to run it with and there is no apphost my.app`))
+			})
+
+			it("errors when the command is not executable", func() {
+				_, err := dotnetexecute.ProcessDefinition{Type: "migrate", Command: "data.txt"}.Process(workingDir, dotnetexecute.AppKindFrameworkDependentDeployment)
+				Expect(err).To(MatchError(ContainSubstring(`command for process "migrate" is not executable`)))
+			})
+		})
+	})
+}
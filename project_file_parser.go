@@ -1,91 +1,495 @@
 package dotnetexecute
 
 import (
-	"encoding/xml"
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 )
 
+// NodeRequirement describes the Node.js tooling that a project needs.
+type NodeRequirement struct {
+	// Required is true when the project builds or runs JavaScript components.
+	Required bool
+
+	// Launch is true when the app also runs Node.js at runtime, for example
+	// to prerender a single-page app on the server. Otherwise Node.js is only
+	// needed to build the app's client assets.
+	Launch bool
+
+	// PackageManager is "npm", "yarn" or "pnpm" when the project uses one.
+	PackageManager string
+
+	// Version and VersionSource give the Node.js version constraint declared
+	// by the JavaScript project, if any.
+	Version       string
+	VersionSource string
+
+	// Reasons explains why the project needs Node.js.
+	Reasons []string
+}
+
 type ProjectFileParser struct{}
 
 func NewProjectFileParser() ProjectFileParser {
 	return ProjectFileParser{}
 }
 
-func (p ProjectFileParser) FindProjectFile(path string) (string, error) {
-	projectFiles, err := filepath.Glob(filepath.Join(path, "*.csproj"))
+// ProjectSelection is the project chosen by FindProjectFile, along with the
+// reasons it was chosen over the other candidates.
+type ProjectSelection struct {
+	Path    string
+	Reasons []string
+}
+
+// FindProjectFile returns the project to build from dir, which is the app
+// root or a directory in it. The project files in dir are considered first
+// and, when there are none, the projects listed by the .sln and .slnx files
+// in dir, leaving out those outside of root. When name is set, the project
+// with that name is chosen, and it is an error when no project has it.
+// Otherwise test projects are left out and executable projects are preferred
+// over libraries. It returns an error when that still leaves more than one
+// candidate, and an empty selection when no project is found, whether or not
+// name is set.
+func (p ProjectFileParser) FindProjectFile(root, dir, name string) (ProjectSelection, error) {
+	var projectFiles []string
+	for _, pattern := range []string{"*.csproj", "*.fsproj", "*.vbproj"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return ProjectSelection{}, err
+		}
+		projectFiles = append(projectFiles, matches...)
+	}
+
+	if len(projectFiles) == 0 {
+		var solutions []string
+		for _, pattern := range []string{"*.sln", "*.slnx"} {
+			matches, err := filepath.Glob(filepath.Join(dir, pattern))
+			if err != nil {
+				return ProjectSelection{}, err
+			}
+			solutions = append(solutions, matches...)
+		}
+
+		for _, solution := range solutions {
+			projects, err := solutionProjects(root, solution)
+			if err != nil {
+				return ProjectSelection{}, err
+			}
+
+			for _, project := range projects {
+				if !slices.Contains(projectFiles, project) {
+					projectFiles = append(projectFiles, project)
+				}
+			}
+		}
+	}
+
+	// A prebuilt app has no project files, so there is nothing for the name
+	// to choose between.
+	if name != "" && len(projectFiles) > 0 {
+		for _, projectFile := range projectFiles {
+			if strings.EqualFold(projectName(projectFile), name) {
+				return ProjectSelection{
+					Path:    projectFile,
+					Reasons: []string{fmt.Sprintf("%s matches BP_DOTNET_PROJECT_NAME=%s", relativeTo(dir, projectFile), name)},
+				}, nil
+			}
+		}
+
+		return ProjectSelection{}, fmt.Errorf("no project named %q found in %s, candidates are: %s", name, dir, formatCandidates(dir, projectFiles))
+	}
+
+	switch len(projectFiles) {
+	case 0:
+		return ProjectSelection{}, nil
+	case 1:
+		return ProjectSelection{
+			Path:    projectFiles[0],
+			Reasons: []string{fmt.Sprintf("%s is the only project", relativeTo(dir, projectFiles[0]))},
+		}, nil
+	}
+
+	var (
+		executables []string
+		libraries   []string
+		reasons     []string
+	)
+	for _, projectFile := range projectFiles {
+		project, err := LoadMSBuildProject(root, projectFile)
+		if err != nil {
+			return ProjectSelection{}, err
+		}
+
+		switch {
+		case isTestProject(project):
+			reasons = append(reasons, fmt.Sprintf("skipped %s: it is a test project", relativeTo(dir, projectFile)))
+		case isExecutableProject(project):
+			executables = append(executables, projectFile)
+		default:
+			libraries = append(libraries, projectFile)
+		}
+	}
+
+	candidates, kind := executables, "executable"
+	if len(executables) > 0 {
+		for _, library := range libraries {
+			reasons = append(reasons, fmt.Sprintf("skipped %s: it is a library and an executable project was found", relativeTo(dir, library)))
+		}
+	} else {
+		candidates, kind = libraries, "non-test"
+	}
+
+	switch len(candidates) {
+	case 0:
+		return ProjectSelection{}, nil
+	case 1:
+		return ProjectSelection{
+			Path:    candidates[0],
+			Reasons: append([]string{fmt.Sprintf("%s is the only %s project", relativeTo(dir, candidates[0]), kind)}, reasons...),
+		}, nil
+	}
+
+	return ProjectSelection{}, fmt.Errorf("found multiple %s projects in %s, set BP_DOTNET_PROJECT_NAME to choose one of: %s", kind, dir, formatCandidates(dir, candidates))
+}
+
+// PackageReferences returns the NuGet packages that the project at path
+// references.
+func (p ProjectFileParser) PackageReferences(root, path string) ([]string, error) {
+	project, err := LoadMSBuildProject(root, path)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	fsProjFiles, err := filepath.Glob(filepath.Join(path, "*.fsproj"))
+	return project.PackageReferences, nil
+}
+
+// RuntimeVersion returns the .NET runtime version that the project at path
+// targets. An explicit RuntimeFrameworkVersion is used as is. Otherwise the
+// version comes from TargetFramework, or the highest .NET version listed in
+// TargetFrameworks, so that net8.0 becomes 8.0.*. It returns an empty string
+// when the project does not target .NET (Core).
+func (p ProjectFileParser) RuntimeVersion(root, path string) (string, error) {
+	project, err := LoadMSBuildProject(root, path)
 	if err != nil {
 		return "", err
 	}
-	projectFiles = append(projectFiles, fsProjFiles...)
 
-	vbProjFiles, err := filepath.Glob(filepath.Join(path, "*.vbproj"))
+	if version := project.Property("RuntimeFrameworkVersion"); version != "" {
+		return version, nil
+	}
+
+	frameworks := splitMSBuildList(project.Property("TargetFrameworks"))
+	if framework := project.Property("TargetFramework"); framework != "" {
+		frameworks = []string{framework}
+	}
+
+	var major, minor int
+	found := false
+	for _, framework := range frameworks {
+		frameworkMajor, frameworkMinor, ok := parseTargetFramework(framework)
+		if !ok {
+			continue
+		}
+
+		if !found || frameworkMajor > major || (frameworkMajor == major && frameworkMinor > minor) {
+			major, minor, found = frameworkMajor, frameworkMinor, true
+		}
+	}
+
+	if !found {
+		return "", nil
+	}
+
+	return fmt.Sprintf("%d.%d.*", major, minor), nil
+}
+
+// ImageMetadata returns the image metadata declared by the project at path.
+// The version comes from Version, or VersionPrefix and VersionSuffix, and the
+// title from Product, falling back to the assembly name as the SDK does.
+func (p ProjectFileParser) ImageMetadata(root, path string) (ImageMetadata, error) {
+	project, err := LoadMSBuildProject(root, path)
 	if err != nil {
-		return "", err
+		return ImageMetadata{}, err
 	}
-	projectFiles = append(projectFiles, vbProjFiles...)
 
-	if len(projectFiles) > 0 {
-		return projectFiles[0], nil
+	version := project.Property("Version")
+	if version == "" {
+		version = project.Property("VersionPrefix")
+		if suffix := project.Property("VersionSuffix"); version != "" && suffix != "" {
+			version = fmt.Sprintf("%s-%s", version, suffix)
+		}
 	}
 
-	return "", nil
+	title := project.Property("Product")
+	if title == "" {
+		title = project.Property("AssemblyName")
+	}
+	if title == "" {
+		title = projectName(path)
+	}
+
+	return ImageMetadata{
+		Version:  version,
+		Title:    title,
+		Authors:  project.Property("Authors"),
+		Source:   project.Property("RepositoryUrl"),
+		Revision: project.Property("SourceRevisionId"),
+		Licenses: project.Property("PackageLicenseExpression"),
+	}, nil
 }
 
-func (p ProjectFileParser) NodeIsRequired(path string) (bool, error) {
-	needsNode, err := findInFile("node ", path)
+var targetFrameworkPattern = regexp.MustCompile(`^(?:net|netcoreapp)(\d+)\.(\d+)(?:-.+)?$`)
+
+// parseTargetFramework returns the .NET version of a target framework
+// moniker such as net8.0, net8.0-windows or netcoreapp3.1. Monikers for
+// .NET Framework and .NET Standard, such as net48 or netstandard2.0, are not
+// recognised.
+func parseTargetFramework(framework string) (int, int, bool) {
+	match := targetFrameworkPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(framework)))
+	if match == nil {
+		return 0, 0, false
+	}
+
+	major, err := strconv.Atoi(match[1])
 	if err != nil {
-		return false, err
+		return 0, 0, false
 	}
 
-	needsNPM, err := findInFile("npm ", path)
+	minor, err := strconv.Atoi(match[2])
 	if err != nil {
-		return false, err
+		return 0, 0, false
 	}
 
-	return needsNode || needsNPM, nil
+	return major, minor, true
+}
+
+// isTestProject reports whether the project holds tests rather than an app.
+func isTestProject(project MSBuildProject) bool {
+	return strings.EqualFold(project.Property("IsTestProject"), "true") || project.HasPackageReference("Microsoft.NET.Test.Sdk")
 }
 
-func (p ProjectFileParser) NPMIsRequired(path string) (bool, error) {
-	return findInFile("npm ", path)
+// isExecutableProject reports whether the project builds an app rather than a
+// library.
+func isExecutableProject(project MSBuildProject) bool {
+	switch strings.ToLower(project.Property("OutputType")) {
+	case "exe", "winexe":
+		return true
+	}
+
+	for _, sdk := range project.SDKs {
+		switch strings.ToLower(sdk) {
+		case "microsoft.net.sdk.web", "microsoft.net.sdk.worker":
+			return true
+		}
+	}
+
+	return false
 }
 
-func findInFile(str, path string) (bool, error) {
-	file, err := os.Open(path)
+func projectName(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+func formatCandidates(root string, paths []string) string {
+	var candidates []string
+	for _, path := range paths {
+		candidates = append(candidates, relativeTo(root, path))
+	}
+
+	return strings.Join(candidates, ", ")
+}
+
+func relativeTo(root, path string) string {
+	if rel, err := filepath.Rel(root, path); err == nil {
+		return rel
+	}
+
+	return path
+}
+
+// NodeRequirement works out whether the project at path needs Node.js. It
+// looks at the commands run by the project's targets, the SpaRoot and
+// SpaProxy settings, and the package.json that SpaRoot points to. Settings
+// that come from imported files and Directory.Build.props/targets count too.
+func (p ProjectFileParser) NodeRequirement(root, path string) (NodeRequirement, error) {
+	project, err := LoadMSBuildProject(root, path)
 	if err != nil {
-		return false, fmt.Errorf("failed to open %s: %w", path, err)
+		return NodeRequirement{}, err
 	}
-	defer func() {
-		_ = file.Close()
-	}()
 
-	var project struct {
-		Targets []struct {
-			Execs []struct {
-				Command string `xml:",attr"`
-			} `xml:"Exec"`
-		} `xml:"Target"`
+	var requirement NodeRequirement
+
+	for _, command := range project.ExecCommands {
+		tool := nodeTool(command)
+		if tool == "" {
+			continue
+		}
+
+		requirement.Required = true
+		requirement.Reasons = append(requirement.Reasons, fmt.Sprintf("a target runs %q", strings.TrimSpace(command)))
+		if requirement.PackageManager == "" && tool != "node" && tool != "npx" {
+			requirement.PackageManager = tool
+		}
 	}
 
-	err = xml.NewDecoder(file).Decode(&project)
+	if project.Property("SpaProxyLaunchCommand") != "" || project.HasPackageReference("Microsoft.AspNetCore.SpaProxy") {
+		requirement.Required = true
+		requirement.Reasons = append(requirement.Reasons, "the project uses the SPA proxy")
+		if requirement.PackageManager == "" {
+			requirement.PackageManager = nodeTool(project.Property("SpaProxyLaunchCommand"))
+		}
+	}
+
+	// Node interop packages run JavaScript on the server, whether or not
+	// anything else in the project needs Node.js.
+	requirement.Launch = needsNodeAtLaunch(project)
+	if requirement.Launch {
+		requirement.Required = true
+		requirement.Reasons = append(requirement.Reasons, "the project runs JavaScript on the server")
+	}
+
+	spaRoot := project.Property("SpaRoot")
+	if !requirement.Required && spaRoot == "" {
+		return NodeRequirement{}, nil
+	}
+
+	packageDir := filepath.Join(filepath.Dir(path), filepath.FromSlash(strings.ReplaceAll(spaRoot, `\`, "/")))
+	packageJSON, found, err := readPackageJSON(filepath.Join(packageDir, "package.json"))
 	if err != nil {
-		return false, fmt.Errorf("failed to decode %s: %w", path, err)
+		return NodeRequirement{}, err
 	}
 
-	for _, target := range project.Targets {
-		for _, exec := range target.Execs {
-			if strings.HasPrefix(exec.Command, str) {
-				return true, nil
-			}
+	if !found && !requirement.Required {
+		return NodeRequirement{}, nil
+	}
+	requirement.Required = true
+
+	if found {
+		requirement.Reasons = append(requirement.Reasons, fmt.Sprintf("found %s", relativeTo(filepath.Dir(path), filepath.Join(packageDir, "package.json"))))
+
+		if manager := packageManager(packageDir, packageJSON); manager != "" {
+			requirement.PackageManager = manager
+		}
+
+		if requirement.PackageManager == "" {
+			requirement.PackageManager = "npm"
+		}
+	}
+
+	if packageJSON.Engines.Node != "" {
+		requirement.Version = packageJSON.Engines.Node
+		requirement.VersionSource = "package.json"
+	} else {
+		content, err := os.ReadFile(filepath.Join(packageDir, ".nvmrc"))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return NodeRequirement{}, err
+		}
+
+		if version := strings.TrimPrefix(strings.TrimSpace(string(content)), "v"); version != "" {
+			requirement.Version = version
+			requirement.VersionSource = ".nvmrc"
+		}
+	}
+
+	return requirement, nil
+}
+
+// nodeRuntimePackages are NuGet packages that call into Node.js while the app
+// is running.
+var nodeRuntimePackages = []string{
+	"Microsoft.AspNetCore.NodeServices",
+	"Microsoft.AspNetCore.SpaServices",
+	"Jering.Javascript.NodeJS",
+}
+
+// needsNodeAtLaunch reports whether the project renders or runs JavaScript on
+// the server, rather than only building client assets with Node.js.
+func needsNodeAtLaunch(p MSBuildProject) bool {
+	if strings.EqualFold(p.Property("BuildServerSideRenderer"), "true") {
+		return true
+	}
+
+	for _, name := range nodeRuntimePackages {
+		if p.HasPackageReference(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var commandSeparator = regexp.MustCompile(`&&|\|\||[;|&\n]`)
+
+// nodeTool returns the Node.js tool (node, npm, npx, yarn or pnpm) that a
+// command line invokes, if any.
+func nodeTool(command string) string {
+	for _, part := range commandSeparator.Split(command, -1) {
+		fields := strings.Fields(part)
+		for len(fields) > 0 && strings.Contains(fields[0], "=") {
+			fields = fields[1:]
+		}
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch tool := fields[0]; tool {
+		case "node", "npm", "npx", "yarn", "pnpm":
+			return tool
+		}
+	}
+
+	return ""
+}
+
+type packageJSON struct {
+	PackageManager string `json:"packageManager"`
+	Engines        struct {
+		Node string `json:"node"`
+	} `json:"engines"`
+}
+
+func readPackageJSON(path string) (packageJSON, bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return packageJSON{}, false, nil
+		}
+		return packageJSON{}, false, err
+	}
+
+	var pkg packageJSON
+	err = json.NewDecoder(bytes.NewReader(content)).Decode(&pkg)
+	if err != nil {
+		return packageJSON{}, false, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	return pkg, true, nil
+}
+
+// packageManager picks the package manager declared by package.json or
+// implied by the lockfile next to it.
+func packageManager(dir string, pkg packageJSON) string {
+	if name, _, _ := strings.Cut(pkg.PackageManager, "@"); name != "" {
+		return name
+	}
+
+	for _, lockfile := range []struct{ name, manager string }{
+		{"yarn.lock", "yarn"},
+		{"pnpm-lock.yaml", "pnpm"},
+		{"package-lock.json", "npm"},
+	} {
+		if _, err := os.Stat(filepath.Join(dir, lockfile.name)); err == nil {
+			return lockfile.manager
 		}
 	}
 
-	return false, nil
+	return ""
 }
@@ -1,6 +1,7 @@
 package dotnetexecute_test
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -32,10 +33,18 @@ func testProjectFileParser(t *testing.T, context spec.G, it spec.S) {
 			Expect(os.RemoveAll(path)).To(Succeed())
 		})
 
-		it("returns an empty string and no error", func() {
-			projectFilePath, err := parser.FindProjectFile(path)
+		it("returns an empty selection and no error", func() {
+			selection, err := parser.FindProjectFile(path, path, "")
 			Expect(err).NotTo(HaveOccurred())
-			Expect(projectFilePath).To(Equal(""))
+			Expect(selection.Path).To(Equal(""))
+		})
+
+		context("when a project name is given but there are no projects", func() {
+			it("returns an empty selection and no error", func() {
+				selection, err := parser.FindProjectFile(path, path, "App")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(selection.Path).To(Equal(""))
+			})
 		})
 
 		context("when there is a csproj", func() {
@@ -44,9 +53,12 @@ func testProjectFileParser(t *testing.T, context spec.G, it spec.S) {
 			})
 
 			it("returns the path to it", func() {
-				projectFilePath, err := parser.FindProjectFile(path)
+				selection, err := parser.FindProjectFile(path, path, "")
 				Expect(err).NotTo(HaveOccurred())
-				Expect(projectFilePath).To(Equal(filepath.Join(path, "app.csproj")))
+				Expect(selection).To(Equal(dotnetexecute.ProjectSelection{
+					Path:    filepath.Join(path, "app.csproj"),
+					Reasons: []string{"app.csproj is the only project"},
+				}))
 			})
 		})
 
@@ -56,9 +68,9 @@ func testProjectFileParser(t *testing.T, context spec.G, it spec.S) {
 			})
 
 			it("returns the path to it", func() {
-				projectFilePath, err := parser.FindProjectFile(path)
+				selection, err := parser.FindProjectFile(path, path, "")
 				Expect(err).NotTo(HaveOccurred())
-				Expect(projectFilePath).To(Equal(filepath.Join(path, "app.fsproj")))
+				Expect(selection.Path).To(Equal(filepath.Join(path, "app.fsproj")))
 			})
 		})
 
@@ -68,39 +80,391 @@ func testProjectFileParser(t *testing.T, context spec.G, it spec.S) {
 			})
 
 			it("returns the path to it", func() {
-				projectFilePath, err := parser.FindProjectFile(path)
+				selection, err := parser.FindProjectFile(path, path, "")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(selection.Path).To(Equal(filepath.Join(path, "app.vbproj")))
+			})
+		})
+
+		context("when there are several projects", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(path, "App.csproj"), []byte(`<Project Sdk="Microsoft.NET.Sdk.Web" />`), 0600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(path, "Lib.csproj"), []byte(`<Project Sdk="Microsoft.NET.Sdk" />`), 0600)).To(Succeed())
+			})
+
+			it("returns the executable one", func() {
+				selection, err := parser.FindProjectFile(path, path, "")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(selection).To(Equal(dotnetexecute.ProjectSelection{
+					Path: filepath.Join(path, "App.csproj"),
+					Reasons: []string{
+						"App.csproj is the only executable project",
+						"skipped Lib.csproj: it is a library and an executable project was found",
+					},
+				}))
+			})
+
+			context("when a project name is given", func() {
+				it("returns that project", func() {
+					selection, err := parser.FindProjectFile(path, path, "lib")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(selection).To(Equal(dotnetexecute.ProjectSelection{
+						Path:    filepath.Join(path, "Lib.csproj"),
+						Reasons: []string{"Lib.csproj matches BP_DOTNET_PROJECT_NAME=lib"},
+					}))
+				})
+			})
+		})
+
+		context("when there is a test project next to the app", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(path, "App.csproj"), []byte(`<Project Sdk="Microsoft.NET.Sdk" />`), 0600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(path, "App.Tests.csproj"), []byte(`
+					<Project Sdk="Microsoft.NET.Sdk">
+						<PropertyGroup>
+							<OutputType>Exe</OutputType>
+						</PropertyGroup>
+						<ItemGroup>
+							<PackageReference Include="Microsoft.NET.Test.Sdk" Version="17.9.0" />
+						</ItemGroup>
+					</Project>
+				`), 0600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(path, "App.IntegrationTests.fsproj"), []byte(`
+					<Project Sdk="Microsoft.NET.Sdk">
+						<PropertyGroup>
+							<IsTestProject>true</IsTestProject>
+						</PropertyGroup>
+					</Project>
+				`), 0600)).To(Succeed())
+			})
+
+			it("returns the app and says why it skipped the tests", func() {
+				selection, err := parser.FindProjectFile(path, path, "")
 				Expect(err).NotTo(HaveOccurred())
-				Expect(projectFilePath).To(Equal(filepath.Join(path, "app.vbproj")))
+				Expect(selection).To(Equal(dotnetexecute.ProjectSelection{
+					Path: filepath.Join(path, "App.csproj"),
+					Reasons: []string{
+						"App.csproj is the only non-test project",
+						"skipped App.Tests.csproj: it is a test project",
+						"skipped App.IntegrationTests.fsproj: it is a test project",
+					},
+				}))
+			})
+		})
+
+		context("when there are several libraries", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(path, "One.csproj"), []byte(`<Project Sdk="Microsoft.NET.Sdk" />`), 0600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(path, "Two.csproj"), []byte(`<Project Sdk="Microsoft.NET.Sdk" />`), 0600)).To(Succeed())
+			})
+
+			it("returns an error listing them", func() {
+				_, err := parser.FindProjectFile(path, path, "")
+				Expect(err).To(MatchError(fmt.Sprintf("found multiple non-test projects in %s, set BP_DOTNET_PROJECT_NAME to choose one of: One.csproj, Two.csproj", path)))
+			})
+		})
+
+		context("when there is a .sln file", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(path, "src", "Api"), os.ModePerm)).To(Succeed())
+				Expect(os.MkdirAll(filepath.Join(path, "src", "Core"), os.ModePerm)).To(Succeed())
+
+				Expect(os.WriteFile(filepath.Join(path, "src", "Api", "Api.csproj"), []byte(`
+					<Project Sdk="Microsoft.NET.Sdk">
+						<PropertyGroup>
+							<OutputType>Exe</OutputType>
+						</PropertyGroup>
+					</Project>
+				`), 0600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(path, "src", "Core", "Core.fsproj"), []byte(`<Project Sdk="Microsoft.NET.Sdk" />`), 0600)).To(Succeed())
+
+				Expect(os.WriteFile(filepath.Join(path, "App.sln"), []byte(`
+Microsoft Visual Studio Solution File, Format Version 12.00
+Project("{2150E333-8FDC-42A3-9474-1A3956D46DE8}") = "src", "src", "{A1}"
+EndProject
+Project("{FAE04EC0-301F-11D3-BF4B-00C04F79EFBC}") = "Api", "src\Api\Api.csproj", "{B2}"
+EndProject
+Project("{F2A71F9B-5D33-465A-A702-920D77279786}") = "Core", "src\Core\Core.fsproj", "{C3}"
+EndProject
+Project("{FAE04EC0-301F-11D3-BF4B-00C04F79EFBC}") = "Gone", "src\Gone\Gone.csproj", "{D4}"
+EndProject
+`), 0600)).To(Succeed())
+			})
+
+			it("returns the executable project it lists", func() {
+				selection, err := parser.FindProjectFile(path, path, "")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(selection.Path).To(Equal(filepath.Join(path, "src", "Api", "Api.csproj")))
+			})
+		})
+
+		context("when a solution lists a project outside of the app root", func() {
+			var appDir string
+
+			it.Before(func() {
+				appDir = filepath.Join(path, "app")
+				Expect(os.MkdirAll(filepath.Join(appDir, "Api"), os.ModePerm)).To(Succeed())
+				Expect(os.MkdirAll(filepath.Join(path, "Other"), os.ModePerm)).To(Succeed())
+
+				Expect(os.WriteFile(filepath.Join(appDir, "Api", "Api.csproj"), []byte(`<Project Sdk="Microsoft.NET.Sdk" />`), 0600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(path, "Other", "Other.csproj"), []byte(`<Project Sdk="Microsoft.NET.Sdk.Web" />`), 0600)).To(Succeed())
+
+				Expect(os.WriteFile(filepath.Join(appDir, "App.sln"), []byte(`
+Microsoft Visual Studio Solution File, Format Version 12.00
+Project("{FAE04EC0-301F-11D3-BF4B-00C04F79EFBC}") = "Api", "Api\Api.csproj", "{B2}"
+EndProject
+Project("{FAE04EC0-301F-11D3-BF4B-00C04F79EFBC}") = "Other", "..\Other\Other.csproj", "{C3}"
+EndProject
+`), 0600)).To(Succeed())
+			})
+
+			it("leaves that project out", func() {
+				selection, err := parser.FindProjectFile(appDir, appDir, "")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(selection).To(Equal(dotnetexecute.ProjectSelection{
+					Path:    filepath.Join(appDir, "Api", "Api.csproj"),
+					Reasons: []string{filepath.Join("Api", "Api.csproj") + " is the only project"},
+				}))
+			})
+		})
+
+		context("when there is a .slnx file", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(path, "src", "Web"), os.ModePerm)).To(Succeed())
+				Expect(os.MkdirAll(filepath.Join(path, "src", "Worker"), os.ModePerm)).To(Succeed())
+
+				Expect(os.WriteFile(filepath.Join(path, "src", "Web", "Web.csproj"), []byte(`<Project Sdk="Microsoft.NET.Sdk.Web" />`), 0600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(path, "src", "Worker", "Worker.csproj"), []byte(`<Project Sdk="Microsoft.NET.Sdk.Worker/8.0.0" />`), 0600)).To(Succeed())
+
+				Expect(os.WriteFile(filepath.Join(path, "App.slnx"), []byte(`
+					<Solution>
+						<Folder Name="/src/">
+							<Project Path="src/Web/Web.csproj" />
+							<Project Path="src/Worker/Worker.csproj" />
+						</Folder>
+					</Solution>
+				`), 0600)).To(Succeed())
+			})
+
+			context("when a project name is given", func() {
+				it("returns that project", func() {
+					selection, err := parser.FindProjectFile(path, path, "Worker")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(selection.Path).To(Equal(filepath.Join(path, "src", "Worker", "Worker.csproj")))
+				})
+			})
+
+			context("when there are several executable projects", func() {
+				it("returns an error listing them", func() {
+					_, err := parser.FindProjectFile(path, path, "")
+					Expect(err).To(MatchError(fmt.Sprintf("found multiple executable projects in %s, set BP_DOTNET_PROJECT_NAME to choose one of: %s, %s",
+						path, filepath.Join("src", "Web", "Web.csproj"), filepath.Join("src", "Worker", "Worker.csproj"))))
+				})
+			})
+
+			context("when no project has the given name", func() {
+				it("returns an error listing the projects", func() {
+					_, err := parser.FindProjectFile(path, path, "Admin")
+					Expect(err).To(MatchError(ContainSubstring(`no project named "Admin" found in`)))
+					Expect(err).To(MatchError(ContainSubstring(filepath.Join("src", "Web", "Web.csproj"))))
+				})
 			})
 		})
 
 		context("failure cases", func() {
 			context("when file pattern matching fails", func() {
 				it("returns the error", func() {
-					_, err := parser.FindProjectFile(`\`)
+					_, err := parser.FindProjectFile(`\`, `\`, "")
 					Expect(err).To(MatchError("syntax error in pattern"))
 				})
 			})
 
+			context("when one of several project files can not be decoded", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(path, "App.csproj"), []byte("%%%"), 0600)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(path, "Lib.csproj"), []byte("<Project />"), 0600)).To(Succeed())
+				})
+
+				it("returns the error", func() {
+					_, err := parser.FindProjectFile(path, path, "")
+					Expect(err).To(MatchError(ContainSubstring("failed to decode")))
+				})
+			})
 		})
 	})
 
-	context("NodeIsRequired", func() {
-		var path string
+	context("RuntimeVersion", func() {
+		var (
+			workingDir string
+			path       string
+		)
 
 		it.Before(func() {
-			file, err := os.CreateTemp("", "app.csproj")
+			var err error
+			workingDir, err = os.MkdirTemp("", "working-dir")
 			Expect(err).NotTo(HaveOccurred())
-			defer func() {
-				err = file.Close()
+
+			path = filepath.Join(workingDir, "app.csproj")
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(workingDir)).To(Succeed())
+		})
+
+		it("returns a constraint for the target framework", func() {
+			for _, c := range []struct {
+				properties string
+				version    string
+			}{
+				{`<TargetFramework>net8.0</TargetFramework>`, "8.0.*"},
+				{`<TargetFramework>net9.0-windows</TargetFramework>`, "9.0.*"},
+				{`<TargetFramework>netcoreapp3.1</TargetFramework>`, "3.1.*"},
+				{`<TargetFrameworks>netstandard2.0;net6.0;net8.0</TargetFrameworks>`, "8.0.*"},
+				{`<TargetFramework>net8.0</TargetFramework><RuntimeFrameworkVersion>8.0.4</RuntimeFrameworkVersion>`, "8.0.4"},
+				{`<TargetFramework>net48</TargetFramework>`, ""},
+				{``, ""},
+			} {
+				Expect(os.WriteFile(path, []byte(`<Project><PropertyGroup>`+c.properties+`</PropertyGroup></Project>`), 0600)).To(Succeed())
+
+				version, err := parser.RuntimeVersion(workingDir, path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(Equal(c.version), c.properties)
+			}
+		})
+
+		context("when the target framework comes from Directory.Build.props", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "Directory.Build.props"), []byte(`
+					<Project>
+						<PropertyGroup>
+							<AppTargetFramework>net9.0</AppTargetFramework>
+						</PropertyGroup>
+					</Project>
+				`), 0600)).To(Succeed())
+
+				Expect(os.WriteFile(path, []byte(`
+					<Project>
+						<PropertyGroup>
+							<TargetFramework>$(AppTargetFramework)</TargetFramework>
+						</PropertyGroup>
+					</Project>
+				`), 0600)).To(Succeed())
+			})
+
+			it("uses it", func() {
+				version, err := parser.RuntimeVersion(workingDir, path)
 				Expect(err).NotTo(HaveOccurred())
-			}()
+				Expect(version).To(Equal("9.0.*"))
+			})
+		})
 
-			path = file.Name()
+		context("failure cases", func() {
+			context("when the file can not be decoded", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(path, []byte("%%%"), 0600)).To(Succeed())
+				})
+
+				it("errors", func() {
+					_, err := parser.RuntimeVersion(workingDir, path)
+					Expect(err).To(MatchError(ContainSubstring("failed to decode")))
+				})
+			})
+		})
+	})
+
+	context("ImageMetadata", func() {
+		var (
+			workingDir string
+			path       string
+		)
+
+		it.Before(func() {
+			var err error
+			workingDir, err = os.MkdirTemp("", "working-dir")
+			Expect(err).NotTo(HaveOccurred())
+
+			path = filepath.Join(workingDir, "app.csproj")
 		})
 
 		it.After(func() {
-			Expect(os.RemoveAll(path)).To(Succeed())
+			Expect(os.RemoveAll(workingDir)).To(Succeed())
+		})
+
+		it("returns the project's metadata", func() {
+			Expect(os.WriteFile(path, []byte(`
+				<Project>
+					<PropertyGroup>
+						<Version>1.2.3</Version>
+						<Product>My App</Product>
+						<Authors>Jane Doe</Authors>
+						<RepositoryUrl>https://github.com/example/my-app</RepositoryUrl>
+						<SourceRevisionId>0123abcd</SourceRevisionId>
+						<PackageLicenseExpression>Apache-2.0</PackageLicenseExpression>
+					</PropertyGroup>
+				</Project>
+			`), 0600)).To(Succeed())
+
+			metadata, err := parser.ImageMetadata(workingDir, path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(metadata).To(Equal(dotnetexecute.ImageMetadata{
+				Version:  "1.2.3",
+				Title:    "My App",
+				Authors:  "Jane Doe",
+				Source:   "https://github.com/example/my-app",
+				Revision: "0123abcd",
+				Licenses: "Apache-2.0",
+			}))
+		})
+
+		it("falls back to the version prefix and assembly or project name", func() {
+			for _, c := range []struct {
+				properties string
+				version    string
+				title      string
+			}{
+				{`<VersionPrefix>2.0.0</VersionPrefix><VersionSuffix>beta.1</VersionSuffix><AssemblyName>My.App</AssemblyName>`, "2.0.0-beta.1", "My.App"},
+				{`<VersionPrefix>2.0.0</VersionPrefix>`, "2.0.0", "app"},
+				{``, "", "app"},
+			} {
+				Expect(os.WriteFile(path, []byte(`<Project><PropertyGroup>`+c.properties+`</PropertyGroup></Project>`), 0600)).To(Succeed())
+
+				metadata, err := parser.ImageMetadata(workingDir, path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(metadata.Version).To(Equal(c.version), c.properties)
+				Expect(metadata.Title).To(Equal(c.title), c.properties)
+			}
+		})
+
+		context("failure cases", func() {
+			context("when the file can not be decoded", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(path, []byte("%%%"), 0600)).To(Succeed())
+				})
+
+				it("errors", func() {
+					_, err := parser.ImageMetadata(workingDir, path)
+					Expect(err).To(MatchError(ContainSubstring("failed to decode")))
+				})
+			})
+		})
+	})
+
+	context("NodeRequirement", func() {
+		var (
+			workingDir string
+			path       string
+		)
+
+		it.Before(func() {
+			var err error
+			workingDir, err = os.MkdirTemp("", "working-dir")
+			Expect(err).NotTo(HaveOccurred())
+
+			path = filepath.Join(workingDir, "app.csproj")
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(workingDir)).To(Succeed())
 		})
 
 		context("when project includes target commands that invoke node", func() {
@@ -117,11 +481,13 @@ func testProjectFileParser(t *testing.T, context spec.G, it spec.S) {
 				`), 0600)).To(Succeed())
 			})
 
-			it("returns true", func() {
-				needNode, err := parser.NodeIsRequired(path)
+			it("requires node without a package manager", func() {
+				requirement, err := parser.NodeRequirement(workingDir, path)
 				Expect(err).NotTo(HaveOccurred())
-
-				Expect(needNode).To(BeTrue())
+				Expect(requirement).To(Equal(dotnetexecute.NodeRequirement{
+					Required: true,
+					Reasons:  []string{`a target runs "node --version"`},
+				}))
 			})
 		})
 
@@ -139,121 +505,329 @@ func testProjectFileParser(t *testing.T, context spec.G, it spec.S) {
 				`), 0600)).To(Succeed())
 			})
 
-			it("returns false", func() {
-				needNode, err := parser.NodeIsRequired(path)
+			it("does not require node", func() {
+				requirement, err := parser.NodeRequirement(workingDir, path)
 				Expect(err).NotTo(HaveOccurred())
-
-				Expect(needNode).To(BeFalse())
+				Expect(requirement).To(Equal(dotnetexecute.NodeRequirement{}))
 			})
 		})
 
-		context("when project includes target commands that invoke npm", func() {
+		context("when a conditional target command invokes a package manager", func() {
 			it.Before(func() {
 				Expect(os.WriteFile(path, []byte(`
 					<Project>
-						<Target Name="first-target">
-							<Exec Command="echo hello" />
+						<Target Name="build-client">
+							<Exec Condition="'$(Configuration)' == 'Release'" WorkingDirectory="ClientApp" Command="CI=true yarn install &amp;&amp; yarn build" />
 						</Target>
-						<Target Name="second-target">
-							<Exec Command="npm install" />
+					</Project>
+				`), 0600)).To(Succeed())
+			})
+
+			it("requires node and that package manager", func() {
+				requirement, err := parser.NodeRequirement(workingDir, path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(requirement).To(Equal(dotnetexecute.NodeRequirement{
+					Required:       true,
+					PackageManager: "yarn",
+					Reasons:        []string{`a target runs "CI=true yarn install && yarn build"`},
+				}))
+			})
+		})
+
+		context("when a chained target command invokes pnpm", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte(`
+					<Project>
+						<Target Name="build-client">
+							<Exec Command="cd ClientApp; pnpm install" />
 						</Target>
 					</Project>
 				`), 0600)).To(Succeed())
 			})
 
-			it("returns true", func() {
-				needNode, err := parser.NodeIsRequired(path)
+			it("requires node and pnpm", func() {
+				requirement, err := parser.NodeRequirement(workingDir, path)
 				Expect(err).NotTo(HaveOccurred())
+				Expect(requirement).To(Equal(dotnetexecute.NodeRequirement{
+					Required:       true,
+					PackageManager: "pnpm",
+					Reasons:        []string{`a target runs "cd ClientApp; pnpm install"`},
+				}))
+			})
+		})
 
-				Expect(needNode).To(BeTrue())
+		context("when a target command only mentions node in an argument", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte(`
+					<Project>
+						<Target Name="first-target">
+							<Exec Command="echo node npm" />
+						</Target>
+					</Project>
+				`), 0600)).To(Succeed())
+			})
+
+			it("does not require node", func() {
+				requirement, err := parser.NodeRequirement(workingDir, path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(requirement).To(Equal(dotnetexecute.NodeRequirement{}))
 			})
 		})
 
-		context("failure cases", func() {
-			context("when the file can not be opened", func() {
+		context("when the project sets a SpaRoot with a package.json", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte(`
+					<Project>
+						<PropertyGroup>
+							<SpaRoot>ClientApp\</SpaRoot>
+						</PropertyGroup>
+					</Project>
+				`), 0600)).To(Succeed())
+
+				Expect(os.MkdirAll(filepath.Join(workingDir, "ClientApp"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, "ClientApp", "package.json"), []byte(`{
+					"engines": { "node": ">=18 <21" }
+				}`), 0600)).To(Succeed())
+			})
+
+			it("requires node and npm with the package.json engine version", func() {
+				requirement, err := parser.NodeRequirement(workingDir, path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(requirement).To(Equal(dotnetexecute.NodeRequirement{
+					Required:       true,
+					PackageManager: "npm",
+					Version:        ">=18 <21",
+					VersionSource:  "package.json",
+					Reasons:        []string{"found ClientApp/package.json"},
+				}))
+			})
+
+			context("when the package.json declares a packageManager", func() {
 				it.Before(func() {
-					Expect(os.RemoveAll(path)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(workingDir, "ClientApp", "package.json"), []byte(`{
+						"packageManager": "pnpm@8.15.1"
+					}`), 0600)).To(Succeed())
 				})
 
-				it("errors", func() {
-					_, err := parser.NodeIsRequired(path)
-					Expect(err.Error()).To(ContainSubstring("failed to open"))
+				it("uses that package manager", func() {
+					requirement, err := parser.NodeRequirement(workingDir, path)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(requirement.PackageManager).To(Equal("pnpm"))
 				})
 			})
 
-			context("when the file can not be decoded", func() {
+			context("when there is a yarn.lock", func() {
 				it.Before(func() {
-					Expect(os.WriteFile(path, []byte("%%%"), 0644)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(workingDir, "ClientApp", "yarn.lock"), nil, 0600)).To(Succeed())
 				})
 
-				it("errors", func() {
-					_, err := parser.NodeIsRequired(path)
-					Expect(err.Error()).To(ContainSubstring("failed to decode"))
+				it("uses yarn", func() {
+					requirement, err := parser.NodeRequirement(workingDir, path)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(requirement.PackageManager).To(Equal("yarn"))
 				})
 			})
 
+			context("when the version comes from an .nvmrc", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(workingDir, "ClientApp", "package.json"), []byte(`{}`), 0600)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(workingDir, "ClientApp", ".nvmrc"), []byte("v20.11.0\n"), 0600)).To(Succeed())
+				})
+
+				it("uses the .nvmrc version", func() {
+					requirement, err := parser.NodeRequirement(workingDir, path)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(requirement.Version).To(Equal("20.11.0"))
+					Expect(requirement.VersionSource).To(Equal(".nvmrc"))
+				})
+			})
 		})
-	})
 
-	context("NPMIsRequired", func() {
-		var path string
+		context("when a Directory.Build.targets file runs the package manager", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte(`
+					<Project>
+						<PropertyGroup>
+							<BuildClient>true</BuildClient>
+						</PropertyGroup>
+					</Project>
+				`), 0600)).To(Succeed())
 
-		it.Before(func() {
-			file, err := os.CreateTemp("", "app.csproj")
-			Expect(err).NotTo(HaveOccurred())
-			defer func() {
-				err = file.Close()
+				Expect(os.WriteFile(filepath.Join(workingDir, "Directory.Build.targets"), []byte(`
+					<Project>
+						<Target Name="build-client" Condition="'$(BuildClient)' == 'true'">
+							<Exec Command="pnpm install" />
+						</Target>
+					</Project>
+				`), 0600)).To(Succeed())
+			})
+
+			it("requires node and that package manager", func() {
+				requirement, err := parser.NodeRequirement(workingDir, path)
 				Expect(err).NotTo(HaveOccurred())
-			}()
+				Expect(requirement).To(Equal(dotnetexecute.NodeRequirement{
+					Required:       true,
+					PackageManager: "pnpm",
+					Reasons:        []string{`a target runs "pnpm install"`},
+				}))
+			})
+		})
 
-			path = file.Name()
+		context("when the project prerenders on the server", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte(`
+					<Project>
+						<PropertyGroup>
+							<SpaRoot>ClientApp</SpaRoot>
+						</PropertyGroup>
+						<ItemGroup>
+							<PackageReference Include="Microsoft.AspNetCore.SpaServices" Version="3.1.0" />
+						</ItemGroup>
+					</Project>
+				`), 0600)).To(Succeed())
+
+				Expect(os.MkdirAll(filepath.Join(workingDir, "ClientApp"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, "ClientApp", "package.json"), []byte(`{}`), 0600)).To(Succeed())
+			})
+
+			it("requires node at launch", func() {
+				requirement, err := parser.NodeRequirement(workingDir, path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(requirement.Launch).To(BeTrue())
+			})
 		})
 
-		it.After(func() {
-			Expect(os.RemoveAll(path)).To(Succeed())
+		context("when the project only references a Node interop package", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte(`
+					<Project>
+						<ItemGroup>
+							<PackageReference Include="Jering.Javascript.NodeJS" Version="7.0.0" />
+						</ItemGroup>
+					</Project>
+				`), 0600)).To(Succeed())
+			})
+
+			it("requires node at launch", func() {
+				requirement, err := parser.NodeRequirement(workingDir, path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(requirement).To(Equal(dotnetexecute.NodeRequirement{
+					Required: true,
+					Launch:   true,
+					Reasons:  []string{"the project runs JavaScript on the server"},
+				}))
+			})
 		})
 
-		context("when project includes target commands that invoke npm", func() {
+		context("when the project builds a server-side renderer", func() {
 			it.Before(func() {
 				Expect(os.WriteFile(path, []byte(`
 					<Project>
-						<Target Name="first-target">
-							<Exec Command="echo hello" />
-						</Target>
-						<Target Name="second-target">
-							<Exec Command="npm install" />
+						<PropertyGroup>
+							<BuildServerSideRenderer>true</BuildServerSideRenderer>
+						</PropertyGroup>
+						<Target Name="build-client">
+							<Exec Command="npm run build:ssr" />
 						</Target>
 					</Project>
 				`), 0600)).To(Succeed())
 			})
 
-			it("returns true", func() {
-				needNode, err := parser.NPMIsRequired(path)
+			it("requires node at launch", func() {
+				requirement, err := parser.NodeRequirement(workingDir, path)
 				Expect(err).NotTo(HaveOccurred())
+				Expect(requirement).To(Equal(dotnetexecute.NodeRequirement{
+					Required:       true,
+					Launch:         true,
+					PackageManager: "npm",
+					Reasons: []string{
+						`a target runs "npm run build:ssr"`,
+						"the project runs JavaScript on the server",
+					},
+				}))
+			})
+		})
+
+		context("when the project sets a SpaRoot without a package.json", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte(`
+					<Project>
+						<PropertyGroup>
+							<SpaRoot>ClientApp\</SpaRoot>
+						</PropertyGroup>
+					</Project>
+				`), 0600)).To(Succeed())
+			})
 
-				Expect(needNode).To(BeTrue())
+			it("does not require node", func() {
+				requirement, err := parser.NodeRequirement(workingDir, path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(requirement).To(Equal(dotnetexecute.NodeRequirement{}))
 			})
 		})
 
-		context("when project does NOT include target commands that invoke node", func() {
+		context("when the project references the SpaProxy package", func() {
 			it.Before(func() {
 				Expect(os.WriteFile(path, []byte(`
 					<Project>
-						<Target Name="first-target">
-							<Exec Command="echo hello" />
-						</Target>
-						<Target Name="second-target">
-							<Exec Command="echo goodbye" />
-						</Target>
+						<PropertyGroup>
+							<SpaProxyLaunchCommand>npm start</SpaProxyLaunchCommand>
+						</PropertyGroup>
+						<ItemGroup>
+							<PackageReference Include="Microsoft.AspNetCore.SpaProxy" Version="8.0.0" />
+						</ItemGroup>
 					</Project>
 				`), 0600)).To(Succeed())
 			})
 
-			it("returns false", func() {
-				needNode, err := parser.NPMIsRequired(path)
+			it("requires node and the launch command's package manager", func() {
+				requirement, err := parser.NodeRequirement(workingDir, path)
 				Expect(err).NotTo(HaveOccurred())
+				Expect(requirement).To(Equal(dotnetexecute.NodeRequirement{
+					Required:       true,
+					PackageManager: "npm",
+					Reasons:        []string{"the project uses the SPA proxy"},
+				}))
+			})
+		})
 
-				Expect(needNode).To(BeFalse())
+		context("failure cases", func() {
+			context("when the file can not be opened", func() {
+				it("errors", func() {
+					_, err := parser.NodeRequirement(workingDir, path)
+					Expect(err).To(MatchError(ContainSubstring("failed to open")))
+				})
+			})
+
+			context("when the file can not be decoded", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(path, []byte("%%%"), 0644)).To(Succeed())
+				})
+
+				it("errors", func() {
+					_, err := parser.NodeRequirement(workingDir, path)
+					Expect(err).To(MatchError(ContainSubstring("failed to decode")))
+				})
+			})
+
+			context("when the package.json can not be decoded", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(path, []byte(`
+						<Project>
+							<PropertyGroup>
+								<SpaRoot>ClientApp</SpaRoot>
+							</PropertyGroup>
+						</Project>
+					`), 0600)).To(Succeed())
+
+					Expect(os.MkdirAll(filepath.Join(workingDir, "ClientApp"), os.ModePerm)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(workingDir, "ClientApp", "package.json"), []byte("%%%"), 0600)).To(Succeed())
+				})
+
+				it("errors", func() {
+					_, err := parser.NodeRequirement(workingDir, path)
+					Expect(err).To(MatchError(ContainSubstring("failed to decode")))
+				})
 			})
 		})
 	})
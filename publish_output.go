@@ -0,0 +1,149 @@
+package dotnetexecute
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// selfContainedHostLibraries are the host libraries that the apphost of a
+// self-contained app loads from the app directory.
+var selfContainedHostLibraries = []string{"libhostfxr.so", "libhostpolicy.so"}
+
+// ValidatePublishOutput cross-checks the publish output in appDir against
+// the app's deps.json and returns the problems that it finds: runtime,
+// native and resource assets that are listed but missing, a deps.json that
+// does not describe the entry assembly or that targets another major and
+// minor runtime version than the runtimeconfig.json, and, for self-contained
+// apps, missing host libraries. A missing deps.json is not a problem, as the
+// host can run an app without one. Single-file and native AOT apps carry
+// their assets inside the executable, so they are not checked.
+func ValidatePublishOutput(appDir string, runtimeConfig RuntimeConfig, kind AppKind) ([]string, error) {
+	if kind == AppKindSingleFile || kind == AppKindNativeAOT {
+		return nil, nil
+	}
+
+	var problems []string
+
+	if kind == AppKindSelfContained {
+		for _, library := range selfContainedHostLibraries {
+			_, err := os.Stat(filepath.Join(appDir, library))
+			if err != nil {
+				if !errors.Is(err, os.ErrNotExist) {
+					return nil, err
+				}
+				problems = append(problems, fmt.Sprintf("self-contained app is missing %s", library))
+			}
+		}
+	}
+
+	depsName := fmt.Sprintf("%s.deps.json", runtimeConfig.AppName)
+	content, err := os.ReadFile(filepath.Join(appDir, depsName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return problems, nil
+		}
+		return nil, err
+	}
+
+	var deps struct {
+		RuntimeTarget struct {
+			Name string `json:"name"`
+		} `json:"runtimeTarget"`
+		Targets map[string]map[string]struct {
+			Runtime   map[string]json.RawMessage `json:"runtime"`
+			Native    map[string]json.RawMessage `json:"native"`
+			Resources map[string]struct {
+				Locale string `json:"locale"`
+			} `json:"resources"`
+		} `json:"targets"`
+		Libraries map[string]struct {
+			Type string `json:"type"`
+		} `json:"libraries"`
+	}
+
+	err = json.Unmarshal(content, &deps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filepath.Join(appDir, depsName), err)
+	}
+
+	targetName := deps.RuntimeTarget.Name
+	if targetName == "" && len(deps.Targets) == 1 {
+		for name := range deps.Targets {
+			targetName = name
+		}
+	}
+
+	// The runtimeconfig.json keeps the framework version that the app was
+	// published for, whatever its roll-forward policy, so its major and minor
+	// must match the runtime target. A wildcard version, which is what an app
+	// without a framework version gets, says nothing about the target and is
+	// not checked.
+	frameworkName, _, _ := strings.Cut(targetName, "/")
+	if version, ok := strings.CutPrefix(frameworkName, ".NETCoreApp,Version=v"); ok {
+		target, targetOK := majorMinor(version)
+		runtime, runtimeOK := majorMinor(runtimeConfig.RuntimeVersion)
+		if targetOK && runtimeOK && target != runtime {
+			problems = append(problems, fmt.Sprintf("%s targets .NET %s, but %s.runtimeconfig.json asks for runtime %s", depsName, version, runtimeConfig.AppName, runtimeConfig.RuntimeVersion))
+		}
+	}
+
+	target := deps.Targets[targetName]
+
+	var ids []string
+	for id := range target {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	entryAssembly := fmt.Sprintf("%s.dll", runtimeConfig.AppName)
+	foundEntryAssembly := false
+	checked := map[string]bool{}
+
+	for _, id := range ids {
+		library := target[id]
+
+		// Publishing flattens the runtime and native assets of packages into
+		// the app directory, and puts resources in a directory per locale.
+		var files []string
+		for asset := range library.Runtime {
+			files = append(files, path.Base(asset))
+			if deps.Libraries[id].Type == "project" && path.Base(asset) == entryAssembly {
+				foundEntryAssembly = true
+			}
+		}
+		for asset := range library.Native {
+			files = append(files, path.Base(asset))
+		}
+		for asset, resource := range library.Resources {
+			files = append(files, path.Join(resource.Locale, path.Base(asset)))
+		}
+		slices.Sort(files)
+
+		for _, file := range files {
+			if checked[file] {
+				continue
+			}
+			checked[file] = true
+
+			_, err := os.Stat(filepath.Join(appDir, filepath.FromSlash(file)))
+			if err != nil {
+				if !errors.Is(err, os.ErrNotExist) {
+					return nil, err
+				}
+				problems = append(problems, fmt.Sprintf("%s lists %s from %s, but it is missing", depsName, file, id))
+			}
+		}
+	}
+
+	if !foundEntryAssembly {
+		problems = append(problems, fmt.Sprintf("%s does not list %s, so it may be from a different build", depsName, entryAssembly))
+	}
+
+	return problems, nil
+}
@@ -0,0 +1,217 @@
+package dotnetexecute_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	dotnetexecute "github.com/paketo-buildpacks/dotnet-execute"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testValidatePublishOutput(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		appDir        string
+		runtimeConfig dotnetexecute.RuntimeConfig
+	)
+
+	it.Before(func() {
+		var err error
+		appDir, err = os.MkdirTemp("", "app")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.WriteFile(filepath.Join(appDir, "myapp.deps.json"), []byte(`{
+			"runtimeTarget": {
+				"name": ".NETCoreApp,Version=v8.0"
+			},
+			"targets": {
+				".NETCoreApp,Version=v8.0": {
+					"myapp/1.0.0": {
+						"dependencies": {
+							"Newtonsoft.Json": "13.0.3"
+						},
+						"runtime": {
+							"myapp.dll": {}
+						},
+						"resources": {
+							"de/myapp.resources.dll": {
+								"locale": "de"
+							}
+						}
+					},
+					"Newtonsoft.Json/13.0.3": {
+						"runtime": {
+							"lib/net6.0/Newtonsoft.Json.dll": {
+								"assemblyVersion": "13.0.0.0"
+							}
+						}
+					},
+					"SQLitePCLRaw.lib.e_sqlite3/2.1.6": {
+						"native": {
+							"runtimes/linux-x64/native/libe_sqlite3.so": {}
+						}
+					}
+				}
+			},
+			"libraries": {
+				"myapp/1.0.0": {
+					"type": "project"
+				},
+				"Newtonsoft.Json/13.0.3": {
+					"type": "package"
+				},
+				"SQLitePCLRaw.lib.e_sqlite3/2.1.6": {
+					"type": "package"
+				}
+			}
+		}`), 0600)).To(Succeed())
+
+		for _, file := range []string{"myapp.dll", "Newtonsoft.Json.dll", "libe_sqlite3.so", "de/myapp.resources.dll"} {
+			Expect(os.MkdirAll(filepath.Dir(filepath.Join(appDir, file)), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(appDir, file), nil, 0600)).To(Succeed())
+		}
+
+		runtimeConfig = dotnetexecute.RuntimeConfig{
+			Path:           filepath.Join(appDir, "myapp.runtimeconfig.json"),
+			AppName:        "myapp",
+			RuntimeVersion: "8.0.0",
+		}
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(appDir)).To(Succeed())
+	})
+
+	it("finds no problems in a complete publish output", func() {
+		problems, err := dotnetexecute.ValidatePublishOutput(appDir, runtimeConfig, dotnetexecute.AppKindFrameworkDependentDeployment)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(problems).To(BeEmpty())
+	})
+
+	context("when assets are missing", func() {
+		it.Before(func() {
+			Expect(os.Remove(filepath.Join(appDir, "Newtonsoft.Json.dll"))).To(Succeed())
+			Expect(os.Remove(filepath.Join(appDir, "libe_sqlite3.so"))).To(Succeed())
+			Expect(os.RemoveAll(filepath.Join(appDir, "de"))).To(Succeed())
+		})
+
+		it("lists each of them", func() {
+			problems, err := dotnetexecute.ValidatePublishOutput(appDir, runtimeConfig, dotnetexecute.AppKindFrameworkDependentDeployment)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(problems).To(Equal([]string{
+				"myapp.deps.json lists Newtonsoft.Json.dll from Newtonsoft.Json/13.0.3, but it is missing",
+				"myapp.deps.json lists libe_sqlite3.so from SQLitePCLRaw.lib.e_sqlite3/2.1.6, but it is missing",
+				"myapp.deps.json lists de/myapp.resources.dll from myapp/1.0.0, but it is missing",
+			}))
+		})
+	})
+
+	context("when the deps.json is from a different build", func() {
+		it.Before(func() {
+			runtimeConfig.AppName = "otherapp"
+			Expect(os.Rename(filepath.Join(appDir, "myapp.deps.json"), filepath.Join(appDir, "otherapp.deps.json"))).To(Succeed())
+		})
+
+		it("reports that it does not list the entry assembly", func() {
+			problems, err := dotnetexecute.ValidatePublishOutput(appDir, runtimeConfig, dotnetexecute.AppKindFrameworkDependentDeployment)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(problems).To(Equal([]string{
+				"otherapp.deps.json does not list otherapp.dll, so it may be from a different build",
+			}))
+		})
+	})
+
+	context("when the runtimeconfig.json asks for another runtime", func() {
+		it.Before(func() {
+			runtimeConfig.RuntimeVersion = "6.0.0"
+		})
+
+		it("reports the mismatch", func() {
+			problems, err := dotnetexecute.ValidatePublishOutput(appDir, runtimeConfig, dotnetexecute.AppKindFrameworkDependentDeployment)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(problems).To(Equal([]string{
+				"myapp.deps.json targets .NET 8.0, but myapp.runtimeconfig.json asks for runtime 6.0.0",
+			}))
+		})
+	})
+
+	context("when the runtimeconfig.json asks for a later patch of the runtime", func() {
+		it.Before(func() {
+			runtimeConfig.RuntimeVersion = "8.0.11"
+		})
+
+		it("reports no problems", func() {
+			problems, err := dotnetexecute.ValidatePublishOutput(appDir, runtimeConfig, dotnetexecute.AppKindFrameworkDependentDeployment)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(problems).To(BeEmpty())
+		})
+	})
+
+	context("when the runtimeconfig.json asks for any runtime", func() {
+		it.Before(func() {
+			runtimeConfig.RuntimeVersion = "*"
+		})
+
+		it("does not check the runtime version", func() {
+			problems, err := dotnetexecute.ValidatePublishOutput(appDir, runtimeConfig, dotnetexecute.AppKindFrameworkDependentDeployment)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(problems).To(BeEmpty())
+		})
+	})
+
+	context("when the app is self-contained", func() {
+		it.Before(func() {
+			runtimeConfig.RuntimeVersion = ""
+			Expect(os.WriteFile(filepath.Join(appDir, "libhostfxr.so"), nil, 0600)).To(Succeed())
+		})
+
+		it("reports missing host libraries", func() {
+			problems, err := dotnetexecute.ValidatePublishOutput(appDir, runtimeConfig, dotnetexecute.AppKindSelfContained)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(problems).To(Equal([]string{
+				"self-contained app is missing libhostpolicy.so",
+			}))
+		})
+	})
+
+	context("when the app is a single-file bundle", func() {
+		it.Before(func() {
+			Expect(os.Remove(filepath.Join(appDir, "Newtonsoft.Json.dll"))).To(Succeed())
+		})
+
+		it("does not check it", func() {
+			problems, err := dotnetexecute.ValidatePublishOutput(appDir, runtimeConfig, dotnetexecute.AppKindSingleFile)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(problems).To(BeEmpty())
+		})
+	})
+
+	context("when there is no deps.json", func() {
+		it.Before(func() {
+			Expect(os.Remove(filepath.Join(appDir, "myapp.deps.json"))).To(Succeed())
+		})
+
+		it("finds no problems", func() {
+			problems, err := dotnetexecute.ValidatePublishOutput(appDir, runtimeConfig, dotnetexecute.AppKindFrameworkDependentDeployment)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(problems).To(BeEmpty())
+		})
+	})
+
+	context("failure cases", func() {
+		context("when the deps.json is malformed", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(appDir, "myapp.deps.json"), []byte("%%%"), 0600)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := dotnetexecute.ValidatePublishOutput(appDir, runtimeConfig, dotnetexecute.AppKindFrameworkDependentDeployment)
+				Expect(err).To(MatchError(ContainSubstring("failed to decode %s", filepath.Join(appDir, "myapp.deps.json"))))
+			})
+		})
+	})
+}
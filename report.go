@@ -0,0 +1,179 @@
+package dotnetexecute
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/paketo-buildpacks/packit/v2"
+)
+
+// ReportLabel is the image label that holds the build report, without its
+// launch environment.
+const ReportLabel = "io.paketo.dotnet-execute.report"
+
+// Report records the decisions that Detect and Build made about the app.
+// Detect fills in the app kind, the files it chose and the build plan
+// requirements. Build reads that report back, when it can, and adds the
+// files, processes and launch environment that it set up, and the problems
+// it found in the publish output. The requirements stay as Detect recorded
+// them, as Build's plan only holds the entries that this buildpack provides.
+type Report struct {
+	AppKind      AppKind             `json:"app_kind,omitempty"`
+	Files        map[string]string   `json:"files,omitempty"`
+	Requirements []ReportRequirement `json:"requirements,omitempty"`
+	Processes    []ReportProcess     `json:"processes,omitempty"`
+
+	// PublishProblems lists what Build found wrong with the publish output.
+	PublishProblems []string `json:"publish_problems,omitempty"`
+
+	// LaunchEnv holds the launch environment of each layer, keyed by layer
+	// name and then by the file name in the layer's env.launch directory.
+	LaunchEnv map[string]map[string]string `json:"launch_env,omitempty"`
+}
+
+// ReportRequirement is a build plan requirement and the reasons for it.
+// Optional requirements are dropped from the plan when no buildpack
+// provides them.
+type ReportRequirement struct {
+	Name          string   `json:"name"`
+	Version       string   `json:"version,omitempty"`
+	VersionSource string   `json:"version_source,omitempty"`
+	Build         bool     `json:"build"`
+	Launch        bool     `json:"launch"`
+	Optional      bool     `json:"optional,omitempty"`
+	Reasons       []string `json:"reasons,omitempty"`
+}
+
+// ReportProcess is a launch process of the app.
+type ReportProcess struct {
+	Type    string   `json:"type"`
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+	Default bool     `json:"default,omitempty"`
+}
+
+// NewReportProcesses converts the app's launch processes for the report.
+func NewReportProcesses(processes []packit.Process) []ReportProcess {
+	var reportProcesses []ReportProcess
+	for _, process := range processes {
+		reportProcesses = append(reportProcesses, ReportProcess{
+			Type:    process.Type,
+			Command: process.Command,
+			Args:    process.Args,
+			Default: process.Default,
+		})
+	}
+
+	return reportProcesses
+}
+
+// newReportRequirement records a build plan requirement with the reasons for
+// it.
+func newReportRequirement(requirement packit.BuildPlanRequirement, optional bool, reasons []string) ReportRequirement {
+	reportRequirement := ReportRequirement{
+		Name:     requirement.Name,
+		Optional: optional,
+		Reasons:  reasons,
+	}
+
+	if metadata, ok := requirement.Metadata.(BuildPlanMetadata); ok {
+		reportRequirement.Version = metadata.Version
+		reportRequirement.VersionSource = metadata.VersionSource
+		reportRequirement.Build = metadata.Build
+		reportRequirement.Launch = metadata.Launch
+	}
+
+	return reportRequirement
+}
+
+// reportPlanRequirements returns the requirements that can be known from
+// the build plan, for when the report from Detect is not available. Build
+// only receives the entries that this buildpack provides, so that is the
+// dotnet-app-kind requirement and nothing else.
+func reportPlanRequirements(plan packit.BuildpackPlan) []ReportRequirement {
+	kind := planAppKind(plan)
+	if kind == "" {
+		return nil
+	}
+
+	return []ReportRequirement{
+		{Name: AppKindPlanEntry, Reasons: []string{fmt.Sprintf("the app kind is %s", kind)}},
+	}
+}
+
+// reportPath returns the file that the report is written to, with a relative
+// BP_DOTNET_REPORT_PATH resolved against the app root, or an empty string
+// when the report is only recorded in the image label.
+func reportPath(config Configuration, workingDir string) string {
+	if config.ReportPath == "" || filepath.IsAbs(config.ReportPath) {
+		return config.ReportPath
+	}
+
+	return filepath.Join(workingDir, config.ReportPath)
+}
+
+// reportLaunchEnv collects the launch and shared environment of the given
+// launch layers.
+func reportLaunchEnv(layers []packit.Layer) map[string]map[string]string {
+	launchEnv := map[string]map[string]string{}
+	for _, layer := range layers {
+		if !layer.Launch || len(layer.LaunchEnv)+len(layer.SharedEnv) == 0 {
+			continue
+		}
+
+		launchEnv[layer.Name] = map[string]string{}
+		for _, env := range []packit.Environment{layer.SharedEnv, layer.LaunchEnv} {
+			for name, value := range env {
+				launchEnv[layer.Name][name] = value
+			}
+		}
+	}
+
+	if len(launchEnv) == 0 {
+		return nil
+	}
+
+	return launchEnv
+}
+
+// ReadReport reads the report at path. A missing file gives an empty report.
+func ReadReport(path string) (Report, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Report{}, nil
+		}
+		return Report{}, fmt.Errorf("failed to read report %s: %w", path, err)
+	}
+
+	var report Report
+	err = json.Unmarshal(content, &report)
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to decode report %s: %w", path, err)
+	}
+
+	return report, nil
+}
+
+// Write writes the report to path as indented JSON, creating its directory.
+func (r Report) Write(path string) error {
+	content, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to write report %s: %w", path, err)
+	}
+
+	err = os.WriteFile(path, append(content, '\n'), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write report %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package dotnetexecute_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/sclevine/spec"
+
+	dotnetexecute "github.com/paketo-buildpacks/dotnet-execute"
+
+	. "github.com/onsi/gomega"
+)
+
+func testReport(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		dir  string
+		path string
+	)
+
+	it.Before(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "report")
+		Expect(err).NotTo(HaveOccurred())
+
+		path = filepath.Join(dir, "reports", "dotnet-execute.json")
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	context("Write", func() {
+		it("creates the directory and writes a report that reads back the same", func() {
+			report := dotnetexecute.Report{
+				AppKind: dotnetexecute.AppKindSource,
+				Files:   map[string]string{"project_file": "/workspace/my.app.csproj"},
+				Requirements: []dotnetexecute.ReportRequirement{
+					{Name: "dotnet-core-aspnet-runtime", Version: "8.0.*", VersionSource: "my.app.csproj", Launch: true, Reasons: []string{"my.app.csproj targets runtime 8.0.*"}},
+					{Name: "node", Build: true, Optional: true, Reasons: []string{"found ClientApp/package.json"}},
+				},
+				Processes: []dotnetexecute.ReportProcess{
+					{Type: "my.app", Command: "/workspace/my.app", Default: true},
+				},
+				PublishProblems: []string{"my.app.deps.json is missing"},
+				LaunchEnv: map[string]map[string]string{
+					"app-kind": {"BPI_DOTNET_APP_KIND.override": "source"},
+				},
+			}
+
+			Expect(report.Write(path)).To(Succeed())
+
+			info, err := os.Stat(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0644)))
+
+			readReport, err := dotnetexecute.ReadReport(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(readReport).To(Equal(report))
+		})
+
+		it("replaces a report that is already there", func() {
+			Expect(dotnetexecute.Report{AppKind: dotnetexecute.AppKindSource}.Write(path)).To(Succeed())
+			Expect(dotnetexecute.Report{AppKind: dotnetexecute.AppKindSelfContained}.Write(path)).To(Succeed())
+
+			report, err := dotnetexecute.ReadReport(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report).To(Equal(dotnetexecute.Report{AppKind: dotnetexecute.AppKindSelfContained}))
+		})
+
+		context("failure cases", func() {
+			context("when the directory can not be created", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(dir, "reports"), nil, 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					err := dotnetexecute.Report{}.Write(path)
+					Expect(err).To(MatchError(ContainSubstring("failed to write report")))
+				})
+			})
+
+			context("when the file can not be written", func() {
+				it.Before(func() {
+					Expect(os.MkdirAll(path, os.ModePerm)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					err := dotnetexecute.Report{}.Write(path)
+					Expect(err).To(MatchError(ContainSubstring("failed to write report")))
+				})
+			})
+		})
+	})
+
+	context("ReadReport", func() {
+		context("when there is no report", func() {
+			it("returns an empty report", func() {
+				report, err := dotnetexecute.ReadReport(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(report).To(Equal(dotnetexecute.Report{}))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the report can not be read", func() {
+				it.Before(func() {
+					Expect(os.MkdirAll(path, os.ModePerm)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := dotnetexecute.ReadReport(path)
+					Expect(err).To(MatchError(ContainSubstring("failed to read report")))
+				})
+			})
+
+			context("when the report can not be decoded", func() {
+				it.Before(func() {
+					Expect(os.MkdirAll(filepath.Dir(path), os.ModePerm)).To(Succeed())
+					Expect(os.WriteFile(path, []byte("%%%"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := dotnetexecute.ReadReport(path)
+					Expect(err).To(MatchError(ContainSubstring("failed to decode report")))
+				})
+			})
+		})
+	})
+
+	context("NewReportProcesses", func() {
+		it("converts the launch processes", func() {
+			Expect(dotnetexecute.NewReportProcesses([]packit.Process{
+				{Type: "web", Command: "dotnet", Args: []string{"my.app.dll"}, Default: true, Direct: true},
+				{Type: "worker", Command: "/workspace/worker"},
+			})).To(Equal([]dotnetexecute.ReportProcess{
+				{Type: "web", Command: "dotnet", Args: []string{"my.app.dll"}, Default: true},
+				{Type: "worker", Command: "/workspace/worker"},
+			}))
+		})
+	})
+}
@@ -29,6 +29,8 @@ func main() {
 	logger := scribe.NewEmitter(os.Stdout).WithLevel(config.LogLevel)
 	configParser := dotnetexecute.NewRuntimeConfigParser()
 	projectParser := dotnetexecute.NewProjectFileParser()
+	processParser := dotnetexecute.NewProcessDescriptorParser()
+	sdkParser := dotnetexecute.NewGlobalJSONParser()
 
 	packit.Run(
 		dotnetexecute.Detect(
@@ -36,10 +38,13 @@ func main() {
 			logger,
 			configParser,
 			projectParser,
+			sdkParser,
 		),
 		dotnetexecute.Build(
 			config,
 			configParser,
+			projectParser,
+			processParser,
 			Generator{},
 			logger,
 			chronos.DefaultClock,
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -49,13 +50,6 @@ func (p RuntimeConfigParser) Parse(glob string) (RuntimeConfig, error) {
 		Path: files[0],
 	}
 
-	var data struct {
-		RuntimeOptions struct {
-			Framework  framework   `json:"framework"`
-			Frameworks []framework `json:"frameworks"`
-		} `json:"runtimeOptions"`
-	}
-
 	file, err := os.Open(config.Path)
 	if err != nil {
 		return RuntimeConfig{}, err
@@ -64,15 +58,44 @@ func (p RuntimeConfigParser) Parse(glob string) (RuntimeConfig, error) {
 		_ = file.Close()
 	}()
 
-	buffer := bytes.NewBuffer(nil)
-	err = jsmin.Min(file, buffer)
+	err = decodeRuntimeConfig(file, &config)
 	if err != nil {
 		return RuntimeConfig{}, err
 	}
 
+	config.AppName = strings.TrimSuffix(filepath.Base(file.Name()), ".runtimeconfig.json")
+
+	info, err := os.Stat(strings.TrimSuffix(file.Name(), ".runtimeconfig.json"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return RuntimeConfig{}, err
+	}
+
+	if info != nil && info.Mode()&0111 != 0 {
+		config.Executable = true
+	}
+
+	return config, nil
+}
+
+// decodeRuntimeConfig reads the runtime and ASP.NET Core versions that a
+// runtimeconfig.json asks for into config.
+func decodeRuntimeConfig(reader io.Reader, config *RuntimeConfig) error {
+	var data struct {
+		RuntimeOptions struct {
+			Framework  framework   `json:"framework"`
+			Frameworks []framework `json:"frameworks"`
+		} `json:"runtimeOptions"`
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	err := jsmin.Min(reader, buffer)
+	if err != nil {
+		return err
+	}
+
 	err = json.NewDecoder(buffer).Decode(&data)
 	if err != nil {
-		return RuntimeConfig{}, err
+		return err
 	}
 
 	switch data.RuntimeOptions.Framework.Name {
@@ -90,12 +113,12 @@ func (p RuntimeConfigParser) Parse(glob string) (RuntimeConfig, error) {
 		switch f.Name {
 		case "Microsoft.NETCore.App":
 			if config.RuntimeVersion != "" {
-				return RuntimeConfig{}, fmt.Errorf("malformed runtimeconfig.json: multiple '%s' frameworks specified", f.Name)
+				return fmt.Errorf("malformed runtimeconfig.json: multiple '%s' frameworks specified", f.Name)
 			}
 			config.RuntimeVersion = versionOrWildcard(f.Version)
 		case "Microsoft.AspNetCore.App":
 			if config.ASPNETVersion != "" {
-				return RuntimeConfig{}, fmt.Errorf("malformed runtimeconfig.json: multiple '%s' frameworks specified", f.Name)
+				return fmt.Errorf("malformed runtimeconfig.json: multiple '%s' frameworks specified", f.Name)
 			}
 			config.ASPNETVersion = versionOrWildcard(f.Version)
 		default:
@@ -103,18 +126,7 @@ func (p RuntimeConfigParser) Parse(glob string) (RuntimeConfig, error) {
 		}
 	}
 
-	config.AppName = strings.TrimSuffix(filepath.Base(file.Name()), ".runtimeconfig.json")
-
-	info, err := os.Stat(strings.TrimSuffix(file.Name(), ".runtimeconfig.json"))
-	if err != nil && !errors.Is(err, os.ErrNotExist) {
-		return RuntimeConfig{}, err
-	}
-
-	if info != nil && info.Mode()&0111 != 0 {
-		config.Executable = true
-	}
-
-	return config, nil
+	return nil
 }
 
 func versionOrWildcard(version string) string {
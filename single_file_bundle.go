@@ -0,0 +1,230 @@
+package dotnetexecute
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// bundleSignature follows the header offset that `dotnet publish
+// -p:PublishSingleFile=true` writes into the apphost. It is the SHA-256 of
+// ".net core bundle".
+var bundleSignature = []byte{
+	0x8b, 0x12, 0x02, 0xb9, 0x6a, 0x61, 0x20, 0x38,
+	0x72, 0x7b, 0x93, 0x02, 0x14, 0xd7, 0xa0, 0x32,
+	0x13, 0xf5, 0xb9, 0xe6, 0xef, 0xae, 0x33, 0x18,
+	0xee, 0x3b, 0x2d, 0xce, 0x24, 0xb3, 0x6a, 0xae,
+}
+
+// Types of the files in a single-file bundle, as written by the SDK.
+const (
+	bundleFileUnknown = iota
+	bundleFileAssembly
+	bundleFileNativeBinary
+	bundleFileDepsJSON
+	bundleFileRuntimeConfigJSON
+	bundleFileSymbols
+)
+
+// bundleFlagCompatMode makes the host extract every file, as .NET Core 3
+// bundles did.
+const bundleFlagCompatMode = 1
+
+// SingleFileBundle describes a single-file app. Extracted lists the files
+// that the host writes to DOTNET_BUNDLE_EXTRACT_BASE_DIR, or to a directory
+// under TMPDIR, before the app starts. RuntimeConfig holds the bundled
+// runtimeconfig.json of bundles from .NET 5 on.
+type SingleFileBundle struct {
+	Path          string
+	MajorVersion  uint32
+	Extracted     []string
+	RuntimeConfig []byte
+}
+
+// bundleLocation is the place of a file in a bundle.
+type bundleLocation struct {
+	Offset, Size int64
+}
+
+// ReadSingleFileBundle reads the bundle manifest of the executable at path.
+// It reports false when the file is not a single-file bundle.
+func ReadSingleFileBundle(path string) (SingleFileBundle, bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return SingleFileBundle{}, false, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	headerOffset, err := findBundleHeaderOffset(file)
+	if err != nil {
+		return SingleFileBundle{}, false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	// An apphost that is not a bundle carries the signature with a zero offset.
+	if headerOffset <= 0 {
+		return SingleFileBundle{}, false, nil
+	}
+
+	bundle, runtimeConfig, err := readBundleManifest(bufio.NewReader(io.NewSectionReader(file, headerOffset, 1<<62)))
+	if err != nil {
+		return SingleFileBundle{}, false, fmt.Errorf("failed to read the bundle manifest of %s: %w", path, err)
+	}
+	bundle.Path = path
+
+	if runtimeConfig.Size > 0 {
+		bundle.RuntimeConfig = make([]byte, runtimeConfig.Size)
+		_, err = file.ReadAt(bundle.RuntimeConfig, runtimeConfig.Offset)
+		if err != nil {
+			return SingleFileBundle{}, false, fmt.Errorf("failed to read the bundled runtimeconfig.json of %s: %w", path, err)
+		}
+	}
+
+	return bundle, true, nil
+}
+
+// findBundleHeaderOffset returns the header offset stored just before the
+// bundle signature, or 0 when there is no signature.
+func findBundleHeaderOffset(file *os.File) (int64, error) {
+	const chunkSize = 1 << 20
+	overlap := len(bundleSignature) + 8
+
+	buffer := make([]byte, chunkSize+overlap)
+	kept := 0
+
+	for {
+		n, err := io.ReadFull(file, buffer[kept:])
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return 0, err
+		}
+
+		window := buffer[:kept+n]
+		if index := bytes.Index(window, bundleSignature); index >= 8 {
+			return int64(binary.LittleEndian.Uint64(window[index-8 : index])), nil
+		}
+
+		if n < len(buffer)-kept {
+			return 0, nil
+		}
+
+		// Keep the tail so that a signature across two chunks is found.
+		copy(buffer, window[len(window)-overlap:])
+		kept = overlap
+	}
+}
+
+// readBundleManifest reads the bundle header and file entries, and returns
+// the location of the bundled runtimeconfig.json.
+func readBundleManifest(reader *bufio.Reader) (SingleFileBundle, bundleLocation, error) {
+	var header struct {
+		MajorVersion uint32
+		MinorVersion uint32
+		FileCount    int32
+	}
+
+	err := binary.Read(reader, binary.LittleEndian, &header)
+	if err != nil {
+		return SingleFileBundle{}, bundleLocation{}, err
+	}
+
+	if header.FileCount < 0 {
+		return SingleFileBundle{}, bundleLocation{}, fmt.Errorf("invalid file count %d", header.FileCount)
+	}
+
+	_, err = readBundleString(reader)
+	if err != nil {
+		return SingleFileBundle{}, bundleLocation{}, err
+	}
+
+	bundle := SingleFileBundle{MajorVersion: header.MajorVersion}
+
+	var (
+		flags         uint64
+		runtimeConfig bundleLocation
+	)
+	if header.MajorVersion >= 2 {
+		var locations struct {
+			DepsJSONOffset, DepsJSONSize           int64
+			RuntimeConfigOffset, RuntimeConfigSize int64
+			Flags                                  uint64
+		}
+
+		err = binary.Read(reader, binary.LittleEndian, &locations)
+		if err != nil {
+			return SingleFileBundle{}, bundleLocation{}, err
+		}
+		flags = locations.Flags
+
+		if locations.RuntimeConfigSize < 0 || locations.RuntimeConfigSize > 1<<20 {
+			return SingleFileBundle{}, bundleLocation{}, fmt.Errorf("invalid runtimeconfig.json size %d", locations.RuntimeConfigSize)
+		}
+		runtimeConfig = bundleLocation{Offset: locations.RuntimeConfigOffset, Size: locations.RuntimeConfigSize}
+	}
+
+	// Bundles from before .NET 5 extract everything.
+	extractAll := header.MajorVersion < 2 || flags&bundleFlagCompatMode != 0
+
+	for i := int32(0); i < header.FileCount; i++ {
+		var location bundleLocation
+
+		err = binary.Read(reader, binary.LittleEndian, &location)
+		if err != nil {
+			return SingleFileBundle{}, bundleLocation{}, err
+		}
+
+		if header.MajorVersion >= 6 {
+			var compressedSize int64
+			err = binary.Read(reader, binary.LittleEndian, &compressedSize)
+			if err != nil {
+				return SingleFileBundle{}, bundleLocation{}, err
+			}
+		}
+
+		fileType, err := reader.ReadByte()
+		if err != nil {
+			return SingleFileBundle{}, bundleLocation{}, err
+		}
+
+		name, err := readBundleString(reader)
+		if err != nil {
+			return SingleFileBundle{}, bundleLocation{}, err
+		}
+
+		switch fileType {
+		case bundleFileAssembly, bundleFileDepsJSON, bundleFileRuntimeConfigJSON:
+			if !extractAll {
+				continue
+			}
+		}
+
+		bundle.Extracted = append(bundle.Extracted, name)
+	}
+
+	return bundle, runtimeConfig, nil
+}
+
+// readBundleString reads a string prefixed with its 7-bit encoded length, as
+// written by .NET's BinaryWriter.
+func readBundleString(reader *bufio.Reader) (string, error) {
+	length, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return "", err
+	}
+
+	if length > 1<<16 {
+		return "", fmt.Errorf("invalid string length %d", length)
+	}
+
+	value := make([]byte, length)
+	_, err = io.ReadFull(reader, value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(value), nil
+}
@@ -0,0 +1,210 @@
+package dotnetexecute_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dotnetexecute "github.com/paketo-buildpacks/dotnet-execute"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+var bundleSignature = []byte{
+	0x8b, 0x12, 0x02, 0xb9, 0x6a, 0x61, 0x20, 0x38,
+	0x72, 0x7b, 0x93, 0x02, 0x14, 0xd7, 0xa0, 0x32,
+	0x13, 0xf5, 0xb9, 0xe6, 0xef, 0xae, 0x33, 0x18,
+	0xee, 0x3b, 0x2d, 0xce, 0x24, 0xb3, 0x6a, 0xae,
+}
+
+type bundleFile struct {
+	Type    byte
+	Name    string
+	Content string
+}
+
+// writeSingleFileBundle writes an apphost stand-in with a bundle manifest
+// listing files of the given types: 1 for assemblies, 2 for native
+// binaries, 3 for deps.json and 4 for runtimeconfig.json. The content of a
+// runtimeconfig.json is embedded and its location put in the header.
+func writeSingleFileBundle(path string, majorVersion uint32, flags uint64, files ...bundleFile) error {
+	writeString := func(buffer *bytes.Buffer, value string) {
+		buffer.Write(binary.AppendUvarint(nil, uint64(len(value))))
+		buffer.WriteString(value)
+	}
+
+	apphost := bytes.NewBuffer([]byte("\x7fELF apphost code"))
+	offsetPosition := apphost.Len()
+	apphost.Write(make([]byte, 8))
+	apphost.Write(bundleSignature)
+	apphost.WriteString("embedded files")
+
+	var runtimeConfigOffset, runtimeConfigSize int64
+	for _, file := range files {
+		if file.Type == 4 && file.Content != "" {
+			runtimeConfigOffset, runtimeConfigSize = int64(apphost.Len()), int64(len(file.Content))
+			apphost.WriteString(file.Content)
+		}
+	}
+
+	headerOffset := apphost.Len()
+	_ = binary.Write(apphost, binary.LittleEndian, []uint32{majorVersion, 0, uint32(len(files))})
+	writeString(apphost, "bundle-id")
+	if majorVersion >= 2 {
+		_ = binary.Write(apphost, binary.LittleEndian, []int64{0, 0, runtimeConfigOffset, runtimeConfigSize})
+		_ = binary.Write(apphost, binary.LittleEndian, flags)
+	}
+
+	for _, file := range files {
+		_ = binary.Write(apphost, binary.LittleEndian, []int64{0, 0})
+		if majorVersion >= 6 {
+			_ = binary.Write(apphost, binary.LittleEndian, int64(0))
+		}
+		apphost.WriteByte(file.Type)
+		writeString(apphost, file.Name)
+	}
+
+	content := apphost.Bytes()
+	binary.LittleEndian.PutUint64(content[offsetPosition:], uint64(headerOffset))
+
+	return os.WriteFile(path, content, 0755)
+}
+
+func testReadSingleFileBundle(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		dir, err := os.MkdirTemp("", "bundle")
+		Expect(err).NotTo(HaveOccurred())
+
+		path = filepath.Join(dir, "myapp")
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(filepath.Dir(path))).To(Succeed())
+	})
+
+	it("returns the files that are extracted at startup", func() {
+		Expect(writeSingleFileBundle(path, 6, 0,
+			bundleFile{Type: 1, Name: "myapp.dll"},
+			bundleFile{Type: 2, Name: "libSkiaSharp.so"},
+			bundleFile{Type: 3, Name: "myapp.deps.json"},
+			bundleFile{Type: 4, Name: "myapp.runtimeconfig.json"},
+			bundleFile{Type: 0, Name: "appsettings.json"},
+		)).To(Succeed())
+
+		bundle, ok, err := dotnetexecute.ReadSingleFileBundle(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(bundle).To(Equal(dotnetexecute.SingleFileBundle{
+			Path:         path,
+			MajorVersion: 6,
+			Extracted:    []string{"libSkiaSharp.so", "appsettings.json"},
+		}))
+	})
+
+	context("when the bundle holds a runtimeconfig.json", func() {
+		it("returns its content", func() {
+			Expect(writeSingleFileBundle(path, 6, 0,
+				bundleFile{Type: 1, Name: "myapp.dll"},
+				bundleFile{Type: 4, Name: "myapp.runtimeconfig.json", Content: `{"runtimeOptions": {}}`},
+			)).To(Succeed())
+
+			bundle, ok, err := dotnetexecute.ReadSingleFileBundle(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(string(bundle.RuntimeConfig)).To(Equal(`{"runtimeOptions": {}}`))
+		})
+	})
+
+	context("when the bundle only holds assemblies", func() {
+		it("extracts nothing", func() {
+			Expect(writeSingleFileBundle(path, 2, 0,
+				bundleFile{Type: 1, Name: "myapp.dll"},
+				bundleFile{Type: 3, Name: "myapp.deps.json"},
+			)).To(Succeed())
+
+			bundle, ok, err := dotnetexecute.ReadSingleFileBundle(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(bundle.Extracted).To(BeEmpty())
+		})
+	})
+
+	context("when the bundle was built in .NET Core 3 compatibility mode", func() {
+		it("extracts everything", func() {
+			Expect(writeSingleFileBundle(path, 6, 1,
+				bundleFile{Type: 1, Name: "myapp.dll"},
+				bundleFile{Type: 3, Name: "myapp.deps.json"},
+			)).To(Succeed())
+
+			bundle, ok, err := dotnetexecute.ReadSingleFileBundle(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(bundle.Extracted).To(Equal([]string{"myapp.dll", "myapp.deps.json"}))
+		})
+	})
+
+	context("when the bundle is from .NET Core 3", func() {
+		it("extracts everything", func() {
+			Expect(writeSingleFileBundle(path, 1, 0,
+				bundleFile{Type: 1, Name: "myapp.dll"},
+			)).To(Succeed())
+
+			bundle, ok, err := dotnetexecute.ReadSingleFileBundle(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(bundle.Extracted).To(Equal([]string{"myapp.dll"}))
+		})
+	})
+
+	context("when the file is an apphost without a bundle", func() {
+		it("reports false", func() {
+			content := append([]byte("\x7fELF apphost code"), make([]byte, 8)...)
+			Expect(os.WriteFile(path, append(content, bundleSignature...), 0755)).To(Succeed())
+
+			_, ok, err := dotnetexecute.ReadSingleFileBundle(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	context("when the file has no bundle signature", func() {
+		it("reports false", func() {
+			Expect(os.WriteFile(path, []byte("#!/bin/sh"), 0755)).To(Succeed())
+
+			_, ok, err := dotnetexecute.ReadSingleFileBundle(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	context("failure cases", func() {
+		context("when the manifest is truncated", func() {
+			it("returns an error", func() {
+				Expect(writeSingleFileBundle(path, 6, 0, bundleFile{Type: 1, Name: "myapp.dll"})).To(Succeed())
+
+				content, err := os.ReadFile(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(os.WriteFile(path, content[:len(content)-4], 0755)).To(Succeed())
+
+				_, _, err = dotnetexecute.ReadSingleFileBundle(path)
+				Expect(err).To(MatchError(ContainSubstring("failed to read the bundle manifest of %s", path)))
+			})
+		})
+
+		context("when the file does not exist", func() {
+			it("returns an error", func() {
+				_, _, err := dotnetexecute.ReadSingleFileBundle(path)
+				Expect(err).To(MatchError(ContainSubstring("failed to open")))
+			})
+		})
+	})
+}
@@ -0,0 +1,85 @@
+package dotnetexecute
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var solutionProjectLine = regexp.MustCompile(`^Project\("[^"]*"\)\s*=\s*"[^"]*"\s*,\s*"([^"]+)"`)
+
+// solutionProjects returns the absolute paths of the .NET projects listed in
+// a .sln or .slnx solution file. Solution folders, projects outside of root
+// and projects that are missing from disk are left out.
+func solutionProjects(root, path string) ([]string, error) {
+	var entries []string
+	if strings.EqualFold(filepath.Ext(path), ".slnx") {
+		document, err := decodeMSBuildFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = slnxProjects(document)
+	} else {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if match := solutionProjectLine.FindStringSubmatch(strings.TrimSpace(scanner.Text())); match != nil {
+				entries = append(entries, match[1])
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+
+	var projects []string
+	for _, entry := range entries {
+		project := filepath.Join(filepath.Dir(path), normalizeMSBuildPath(entry))
+		if !isProjectFile(project) || !withinDir(root, project) {
+			continue
+		}
+
+		if info, err := os.Stat(project); err != nil || info.IsDir() {
+			continue
+		}
+
+		projects = append(projects, project)
+	}
+
+	return projects, nil
+}
+
+// slnxProjects collects the Path of every Project element in a .slnx
+// document, including those nested in solution folders.
+func slnxProjects(element msbuildElement) []string {
+	var paths []string
+	for _, child := range element.Children {
+		if child.XMLName.Local == "Project" {
+			paths = append(paths, child.Attr("Path"))
+		}
+		paths = append(paths, slnxProjects(child)...)
+	}
+
+	return paths
+}
+
+func isProjectFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csproj", ".fsproj", ".vbproj":
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,186 @@
+package dotnetexecute
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// StartupHookBindingType is the type of the service binding whose *.dll
+// entries are added to the app as startup hooks.
+const StartupHookBindingType = "dotnet-startup-hooks"
+
+// StartupHookLayerDir is the directory that other buildpacks create in their
+// layers to contribute startup hook assemblies.
+const StartupHookLayerDir = "startup-hooks"
+
+// StartupHook is an assembly that the .NET runtime loads before the app's
+// entry point, along with the framework that it targets.
+type StartupHook struct {
+	Path            string
+	TargetFramework string
+}
+
+// FindLayerStartupHooks returns the assemblies in the startup-hooks
+// directory of each launch layer contributed by the other buildpacks in
+// layersDir's parent directory. Other layers are not part of the app image,
+// so their hooks would be missing at launch.
+func FindLayerStartupHooks(layersDir string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(layersDir), "*", "*", StartupHookLayerDir, "*.dll"))
+	if err != nil {
+		return nil, err
+	}
+
+	var hooks []string
+	for _, match := range matches {
+		if strings.HasPrefix(match, layersDir+string(filepath.Separator)) {
+			continue
+		}
+
+		launch, err := isLaunchLayer(filepath.Dir(filepath.Dir(match)))
+		if err != nil {
+			return nil, err
+		}
+
+		if launch {
+			hooks = append(hooks, match)
+		}
+	}
+
+	return hooks, nil
+}
+
+// isLaunchLayer reads the <layer>.toml file next to layerDir and reports
+// whether it marks the layer as available at launch.
+func isLaunchLayer(layerDir string) (bool, error) {
+	var metadata struct {
+		Types struct {
+			Launch bool `toml:"launch"`
+		} `toml:"types"`
+	}
+
+	_, err := toml.DecodeFile(layerDir+".toml", &metadata)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to decode %s.toml: %w", layerDir, err)
+	}
+
+	return metadata.Types.Launch, nil
+}
+
+// ReadStartupHook reads the TargetFramework attribute of the assembly at path
+// and checks that an app on the given runtime version can load it. Assemblies
+// for .NET Framework never can, .NET Standard assemblies always can, and .NET
+// assemblies can when they target the runtime's version or an earlier one. A
+// runtime version without a numeric major and minor version, such as the
+// empty one of a self-contained app or the * wildcard, skips the version
+// check.
+func ReadStartupHook(path, runtimeVersion string) (StartupHook, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return StartupHook{}, fmt.Errorf("startup hook %s not found", path)
+		}
+		return StartupHook{}, err
+	}
+
+	if !bytes.HasPrefix(content, []byte("MZ")) {
+		return StartupHook{}, fmt.Errorf("startup hook %s is not a .NET assembly", path)
+	}
+
+	hook := StartupHook{Path: path}
+
+	for _, identifier := range []string{".NETCoreApp", ".NETStandard", ".NETFramework"} {
+		version, ok := targetFrameworkVersion(content, identifier)
+		if !ok {
+			continue
+		}
+
+		hook.TargetFramework = fmt.Sprintf("%s,Version=v%s", identifier, version)
+
+		switch identifier {
+		case ".NETFramework":
+			return StartupHook{}, fmt.Errorf("startup hook %s targets %s, which .NET can not load", path, hook.TargetFramework)
+		case ".NETCoreApp":
+			if _, ok := majorMinor(runtimeVersion); ok && compareMajorMinor(version, runtimeVersion) > 0 {
+				return StartupHook{}, fmt.Errorf("startup hook %s targets %s, which is newer than the app's runtime %s", path, hook.TargetFramework, runtimeVersion)
+			}
+		}
+
+		break
+	}
+
+	return hook, nil
+}
+
+// targetFrameworkVersion finds the version in an "<identifier>,Version=vX.Y"
+// TargetFramework attribute value.
+func targetFrameworkVersion(content []byte, identifier string) (string, bool) {
+	marker := []byte(identifier + ",Version=v")
+	index := bytes.Index(content, marker)
+	if index < 0 {
+		return "", false
+	}
+
+	rest := content[index+len(marker):]
+	end := 0
+	for end < len(rest) && (rest[end] == '.' || (rest[end] >= '0' && rest[end] <= '9')) {
+		end++
+	}
+
+	if end == 0 {
+		return "", false
+	}
+
+	return string(rest[:end]), true
+}
+
+// majorMinor returns the "major.minor" part of version. It reports false
+// when version does not start with a numeric major and minor version.
+func majorMinor(version string) (string, bool) {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return "", false
+	}
+
+	for _, part := range parts[:2] {
+		if _, err := strconv.Atoi(part); err != nil {
+			return "", false
+		}
+	}
+
+	return parts[0] + "." + parts[1], true
+}
+
+// compareMajorMinor compares the major and minor parts of two versions.
+func compareMajorMinor(a, b string) int {
+	partsA := strings.SplitN(a, ".", 3)
+	partsB := strings.SplitN(b, ".", 3)
+
+	for i := 0; i < 2; i++ {
+		var x, y int
+		if i < len(partsA) {
+			x, _ = strconv.Atoi(partsA[i])
+		}
+		if i < len(partsB) {
+			y, _ = strconv.Atoi(partsB[i])
+		}
+
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,185 @@
+package dotnetexecute_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	dotnetexecute "github.com/paketo-buildpacks/dotnet-execute"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+// writeAssembly writes a stand-in for a .NET assembly whose
+// TargetFramework attribute has the given value.
+func writeAssembly(path, targetFramework string) error {
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, []byte("MZ\x90\x00...\x1a"+targetFramework+"\x01\x00T\x0eFrameworkDisplayName"), 0644)
+}
+
+func testReadStartupHook(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		dir string
+	)
+
+	it.Before(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "hooks")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	it("returns the framework that the hook targets", func() {
+		path := filepath.Join(dir, "Hook.dll")
+		Expect(writeAssembly(path, ".NETCoreApp,Version=v6.0")).To(Succeed())
+
+		hook, err := dotnetexecute.ReadStartupHook(path, "8.0.4")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hook).To(Equal(dotnetexecute.StartupHook{
+			Path:            path,
+			TargetFramework: ".NETCoreApp,Version=v6.0",
+		}))
+	})
+
+	context("when the hook targets .NET Standard", func() {
+		it("accepts it", func() {
+			path := filepath.Join(dir, "Hook.dll")
+			Expect(writeAssembly(path, ".NETStandard,Version=v2.0")).To(Succeed())
+
+			hook, err := dotnetexecute.ReadStartupHook(path, "8.0.4")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hook.TargetFramework).To(Equal(".NETStandard,Version=v2.0"))
+		})
+	})
+
+	context("when the runtime version is not known", func() {
+		it("skips the version check", func() {
+			path := filepath.Join(dir, "Hook.dll")
+			Expect(writeAssembly(path, ".NETCoreApp,Version=v9.0")).To(Succeed())
+
+			hook, err := dotnetexecute.ReadStartupHook(path, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hook.TargetFramework).To(Equal(".NETCoreApp,Version=v9.0"))
+		})
+	})
+
+	context("when the runtime version is a wildcard", func() {
+		it("skips the version check", func() {
+			path := filepath.Join(dir, "Hook.dll")
+			Expect(writeAssembly(path, ".NETCoreApp,Version=v9.0")).To(Succeed())
+
+			hook, err := dotnetexecute.ReadStartupHook(path, "*")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hook.TargetFramework).To(Equal(".NETCoreApp,Version=v9.0"))
+		})
+	})
+
+	context("when the hook has no TargetFramework attribute", func() {
+		it("accepts it", func() {
+			path := filepath.Join(dir, "Hook.dll")
+			Expect(writeAssembly(path, "")).To(Succeed())
+
+			hook, err := dotnetexecute.ReadStartupHook(path, "8.0.4")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hook.TargetFramework).To(BeEmpty())
+		})
+	})
+
+	context("failure cases", func() {
+		context("when the hook targets a newer runtime", func() {
+			it("returns an error", func() {
+				path := filepath.Join(dir, "Hook.dll")
+				Expect(writeAssembly(path, ".NETCoreApp,Version=v10.0")).To(Succeed())
+
+				_, err := dotnetexecute.ReadStartupHook(path, "8.0.4")
+				Expect(err).To(MatchError(ContainSubstring("targets .NETCoreApp,Version=v10.0, which is newer than the app's runtime 8.0.4")))
+			})
+		})
+
+		context("when the hook targets .NET Framework", func() {
+			it("returns an error", func() {
+				path := filepath.Join(dir, "Hook.dll")
+				Expect(writeAssembly(path, ".NETFramework,Version=v4.8")).To(Succeed())
+
+				_, err := dotnetexecute.ReadStartupHook(path, "8.0.4")
+				Expect(err).To(MatchError(ContainSubstring("targets .NETFramework,Version=v4.8, which .NET can not load")))
+			})
+		})
+
+		context("when the hook is not an assembly", func() {
+			it("returns an error", func() {
+				path := filepath.Join(dir, "Hook.dll")
+				Expect(os.WriteFile(path, []byte("text"), 0644)).To(Succeed())
+
+				_, err := dotnetexecute.ReadStartupHook(path, "8.0.4")
+				Expect(err).To(MatchError(ContainSubstring("is not a .NET assembly")))
+			})
+		})
+
+		context("when the hook does not exist", func() {
+			it("returns an error", func() {
+				_, err := dotnetexecute.ReadStartupHook(filepath.Join(dir, "Missing.dll"), "8.0.4")
+				Expect(err).To(MatchError(ContainSubstring("Missing.dll not found")))
+			})
+		})
+	})
+}
+
+func testFindLayerStartupHooks(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		layersRoot string
+	)
+
+	it.Before(func() {
+		var err error
+		layersRoot, err = os.MkdirTemp("", "layers")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(writeAssembly(filepath.Join(layersRoot, "some-buildpack", "agent", "startup-hooks", "Agent.dll"), "")).To(Succeed())
+		Expect(writeAssembly(filepath.Join(layersRoot, "some-buildpack", "agent", "startup-hooks", "Agent.pdb"), "")).To(Succeed())
+		Expect(writeAssembly(filepath.Join(layersRoot, "some-buildpack", "agent", "Other.dll"), "")).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(layersRoot, "some-buildpack", "agent.toml"), []byte("[types]\n  launch = true\n"), 0600)).To(Succeed())
+		Expect(writeAssembly(filepath.Join(layersRoot, "some-buildpack", "build-only", "startup-hooks", "Build.dll"), "")).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(layersRoot, "some-buildpack", "build-only.toml"), []byte("[types]\n  build = true\n"), 0600)).To(Succeed())
+		Expect(writeAssembly(filepath.Join(layersRoot, "some-buildpack", "no-metadata", "startup-hooks", "Unknown.dll"), "")).To(Succeed())
+		Expect(writeAssembly(filepath.Join(layersRoot, "dotnet-execute", "hooks", "startup-hooks", "Own.dll"), "")).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(layersRoot, "dotnet-execute", "hooks.toml"), []byte("[types]\n  launch = true\n"), 0600)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(layersRoot)).To(Succeed())
+	})
+
+	it("returns the hooks that other buildpacks contribute to launch layers", func() {
+		hooks, err := dotnetexecute.FindLayerStartupHooks(filepath.Join(layersRoot, "dotnet-execute"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hooks).To(Equal([]string{
+			filepath.Join(layersRoot, "some-buildpack", "agent", "startup-hooks", "Agent.dll"),
+		}))
+	})
+
+	context("failure cases", func() {
+		context("when a layer's metadata can not be decoded", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(layersRoot, "some-buildpack", "agent.toml"), []byte("%%%"), 0600)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := dotnetexecute.FindLayerStartupHooks(filepath.Join(layersRoot, "dotnet-execute"))
+				Expect(err).To(MatchError(ContainSubstring("failed to decode %s", filepath.Join(layersRoot, "some-buildpack", "agent.toml"))))
+			})
+		})
+	})
+}